@@ -17,7 +17,7 @@ func main() {
 	}
 
 	// Initialize database
-	db, err := database.InitDB(cfg.DatabaseURL)
+	db, err := database.InitDB(cfg.DatabaseURL, cfg.LateGracePeriod)
 	if err != nil {
 		log.Fatalf("could not initialize database: %v", err)
 	}
@@ -9,11 +9,14 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
-func GenerateJWT(user models.User, cfg *config.Config) (string, error) {
+// GenerateJWT issues a short-lived access token for the user's session. The
+// "sid" claim lets AuthMiddleware reject tokens of revoked sessions.
+func GenerateJWT(user models.User, sessionID uint, cfg *config.Config) (string, error) {
 	claims := jwt.MapClaims{
 		"id":   user.ID,
 		"role": user.Role,
-		"exp":  time.Now().Add(time.Hour * 72).Unix(),
+		"sid":  sessionID,
+		"exp":  time.Now().Add(cfg.AccessTokenTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -27,4 +30,4 @@ func ValidateJWT(tokenString string, cfg *config.Config) (*jwt.Token, error) {
 		}
 		return []byte(cfg.JWTSecret), nil
 	})
-} 
\ No newline at end of file
+}
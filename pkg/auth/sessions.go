@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked
+	// refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again. The token was most likely stolen, so its session
+	// is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// Tokens is the pair handed to the client on login and on refresh.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // Access token lifetime in seconds
+}
+
+// HashToken returns the stored form of a random token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewToken returns a random URL-safe token.
+func NewToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// issue creates a refresh token for the session and a matching access token.
+func issue(tx *gorm.DB, user models.User, session models.AuthSession, now time.Time, cfg *config.Config) (Tokens, error) {
+	refresh, err := NewToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+	if err := tx.Create(&models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: HashToken(refresh),
+		ExpiresAt: now.Add(cfg.RefreshTokenTTL),
+	}).Error; err != nil {
+		return Tokens{}, err
+	}
+	access, err := GenerateJWT(user, session.ID, cfg)
+	if err != nil {
+		return Tokens{}, err
+	}
+	return Tokens{AccessToken: access, RefreshToken: refresh, ExpiresIn: int(cfg.AccessTokenTTL / time.Second)}, nil
+}
+
+// StartSession opens a new session for the user after a successful login.
+func StartSession(db *gorm.DB, user models.User, userAgent, ip string, cfg *config.Config) (Tokens, error) {
+	now := time.Now()
+	var tokens Tokens
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Expired tokens can neither be used nor reused, so drop them
+		if err := tx.Where("expires_at < ?", now).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		session := models.AuthSession{UserID: user.ID, UserAgent: userAgent, IP: ip, LastUsedAt: now}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		var err error
+		tokens, err = issue(tx, user, session, now, cfg)
+		return err
+	})
+	return tokens, err
+}
+
+// Refresh exchanges a refresh token for a new token pair. Every refresh token
+// works once; presenting a used one revokes the whole session.
+func Refresh(db *gorm.DB, refreshToken string, cfg *config.Config) (Tokens, error) {
+	now := time.Now()
+	var stored models.RefreshToken
+	err := db.Preload("Session.User").Where("token_hash = ?", HashToken(refreshToken)).First(&stored).Error
+	if err == gorm.ErrRecordNotFound {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Tokens{}, err
+	}
+	if stored.Session.RevokedAt != nil || !stored.ExpiresAt.After(now) {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		if err := RevokeSession(db, stored.SessionID); err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{}, ErrRefreshTokenReused
+	}
+
+	var tokens Tokens
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Claim the token atomically so that two concurrent refreshes cannot
+		// both succeed
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidRefreshToken
+		}
+		if err := tx.Model(&models.AuthSession{}).Where("id = ?", stored.SessionID).Update("last_used_at", now).Error; err != nil {
+			return err
+		}
+		var err error
+		tokens, err = issue(tx, stored.Session.User, stored.Session, now, cfg)
+		return err
+	})
+	return tokens, err
+}
+
+// Logout revokes the session a refresh token belongs to.
+func Logout(db *gorm.DB, refreshToken string) error {
+	var stored models.RefreshToken
+	err := db.Where("token_hash = ?", HashToken(refreshToken)).First(&stored).Error
+	if err == gorm.ErrRecordNotFound {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+	return RevokeSession(db, stored.SessionID)
+}
+
+// RevokeSession ends one session. Its access tokens are rejected from the
+// next request on and its refresh tokens stop working.
+func RevokeSession(db *gorm.DB, sessionID uint) error {
+	return db.Model(&models.AuthSession{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserSessions ends every session of the user and returns how many
+// were still active.
+func RevokeUserSessions(db *gorm.DB, userID uint) (int64, error) {
+	result := db.Model(&models.AuthSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// SessionActive reports whether the session exists and has not been revoked.
+func SessionActive(db *gorm.DB, sessionID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.AuthSession{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"errors"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidUserToken is returned for mailed tokens that are unknown, used,
+// expired or meant for another purpose.
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+
+// IssueUserToken creates a mailed token for the user, replacing the unused
+// tokens they already had for the same purpose.
+func IssueUserToken(db *gorm.DB, userID uint, purpose string, ttl time.Duration) (string, error) {
+	token, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Delete(&models.UserToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: HashToken(token),
+			ExpiresAt: now.Add(ttl),
+		}).Error
+	})
+	return token, err
+}
+
+// ConsumeUserToken marks a mailed token as used and returns it. A token can
+// only be consumed once, even by concurrent requests.
+func ConsumeUserToken(db *gorm.DB, token, purpose string) (models.UserToken, error) {
+	now := time.Now()
+	var stored models.UserToken
+	err := db.Where("token_hash = ? AND purpose = ?", HashToken(token), purpose).First(&stored).Error
+	if err == gorm.ErrRecordNotFound {
+		return stored, ErrInvalidUserToken
+	}
+	if err != nil {
+		return stored, err
+	}
+
+	result := db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", stored.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return stored, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return stored, ErrInvalidUserToken
+	}
+	return stored, nil
+}
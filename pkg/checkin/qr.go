@@ -0,0 +1,49 @@
+package checkin
+
+import (
+	"bytes"
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Image formats the QR code can be rendered in.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Limits of the PNG side length in pixels.
+const (
+	MinSize     = 128
+	MaxSize     = 1024
+	DefaultSize = 512
+)
+
+// PNG renders content as a QR code image size pixels wide.
+func PNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
+
+// SVG renders content as a scalable QR code, one square per module.
+func SVG(content string) ([]byte, error) {
+	q, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := q.Bitmap() // includes the quiet zone
+	n := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, n, n)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,88 @@
+package checkin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that are malformed or were not
+	// signed with the server secret.
+	ErrInvalidToken = errors.New("invalid check-in token")
+	// ErrExpiredToken is returned for correctly signed tokens past their expiry.
+	ErrExpiredToken = errors.New("check-in token expired")
+)
+
+// Claims is what a check-in token carries: the code as a student would type
+// it, plus the lesson and session it was shown for.
+type Claims struct {
+	LessonID  uint   `json:"l"`
+	SessionID uint   `json:"s"`
+	Code      string `json:"c"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// signature tags the payload so that a check-in token can never be confused
+// with another value signed by the same secret.
+func signature(secret, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("checkin."))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Sign encodes claims into a compact token of the form payload.signature.
+func Sign(secret string, claims Claims) (string, error) {
+	raw, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature(secret, payload)), nil
+}
+
+// Parse checks the signature and expiry of token and returns its claims.
+func Parse(secret, token string, now time.Time) (Claims, error) {
+	var claims Claims
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, ErrInvalidToken
+	}
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, signature(secret, payload)) {
+		return claims, ErrInvalidToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return claims, ErrInvalidToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return claims, ErrExpiredToken
+	}
+	return claims, nil
+}
+
+// URL builds the check-in link encoded in the QR code. Opening it in the
+// frontend submits the token for the logged in student.
+func URL(publicURL, token string) string {
+	return strings.TrimRight(publicURL, "/") + "/checkin?t=" + url.QueryEscape(token)
+}
+
+// TokenFromPayload accepts either a bare token or the check-in link built by
+// URL, as scanners may hand over either.
+func TokenFromPayload(payload string) string {
+	payload = strings.TrimSpace(payload)
+	if u, err := url.Parse(payload); err == nil && u.Query().Has("t") {
+		return u.Query().Get("t")
+	}
+	return payload
+}
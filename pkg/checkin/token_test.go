@@ -0,0 +1,80 @@
+package checkin
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret"
+
+func TestSignParse(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	claims := Claims{LessonID: 3, SessionID: 42, Code: "K7M2", ExpiresAt: now.Add(time.Minute).Unix()}
+	token, err := Sign(testSecret, claims)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	payload, sig, _ := strings.Cut(token, ".")
+	other, err := Sign(testSecret, Claims{LessonID: 4, SessionID: 42, Code: "K7M2", ExpiresAt: claims.ExpiresAt})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	otherPayload, _, _ := strings.Cut(other, ".")
+
+	tests := []struct {
+		name    string
+		secret  string
+		token   string
+		now     time.Time
+		wantErr error
+	}{
+		{"valid", testSecret, token, now, nil},
+		{"one second before expiry", testSecret, token, now.Add(time.Minute - time.Second), nil},
+		{"at expiry", testSecret, token, now.Add(time.Minute), ErrExpiredToken},
+		{"after expiry", testSecret, token, now.Add(time.Hour), ErrExpiredToken},
+		{"other secret", "another-secret", token, now, ErrInvalidToken},
+		{"payload swapped", testSecret, otherPayload + "." + sig, now, ErrInvalidToken},
+		{"signature cut", testSecret, payload + "." + sig[:len(sig)-2], now, ErrInvalidToken},
+		{"signature not base64", testSecret, payload + ".!!!", now, ErrInvalidToken},
+		{"no signature", testSecret, payload, now, ErrInvalidToken},
+		{"empty", testSecret, "", now, ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.secret, tt.token, tt.now)
+			if err != tt.wantErr {
+				t.Fatalf("Parse error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got != claims {
+				t.Errorf("Parse claims = %+v, want %+v", got, claims)
+			}
+		})
+	}
+}
+
+func TestURLAndTokenFromPayload(t *testing.T) {
+	token := "eyJsIjozfQ.c2ln+/="
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"bare token", token, token},
+		{"bare token with spaces", "  " + token + "\n", token},
+		{"check-in link", URL("https://attendance.example.com", token), token},
+		{"check-in link with trailing slash", URL("https://attendance.example.com/", token), token},
+		{"link without token", "https://attendance.example.com/checkin", "https://attendance.example.com/checkin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TokenFromPayload(tt.payload); got != tt.want {
+				t.Errorf("TokenFromPayload(%q) = %q, want %q", tt.payload, got, tt.want)
+			}
+		})
+	}
+
+	if got, want := URL("https://attendance.example.com/", "abc"), "https://attendance.example.com/checkin?t=abc"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+}
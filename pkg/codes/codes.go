@@ -0,0 +1,54 @@
+package codes
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// Alphabets attendance codes can be generated from.
+const (
+	AlphabetDigits       = "digits"
+	AlphabetAlphanumeric = "alphanumeric"
+)
+
+// Length limits of an attendance code.
+const (
+	MinLength = 4
+	MaxLength = 12
+)
+
+var alphabets = map[string]string{
+	AlphabetDigits: "0123456789",
+	// Upper case letters and digits without look-alikes: 0/O, 1/I/L
+	AlphabetAlphanumeric: "23456789ABCDEFGHJKMNPQRSTUVWXYZ",
+}
+
+// IsValidAlphabet reports whether name is one of the Alphabet* constants.
+func IsValidAlphabet(name string) bool {
+	_, ok := alphabets[name]
+	return ok
+}
+
+// Generate returns a random code of the given length drawn uniformly from
+// the named alphabet using crypto/rand.
+func Generate(length int, alphabet string) (string, error) {
+	chars, ok := alphabets[alphabet]
+	if !ok {
+		return "", fmt.Errorf("unknown code alphabet %q", alphabet)
+	}
+	if length < MinLength || length > MaxLength {
+		return "", fmt.Errorf("code length must be between %d and %d", MinLength, MaxLength)
+	}
+
+	max := big.NewInt(int64(len(chars)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = chars[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,70 @@
+package codes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		length   int
+		alphabet string
+		wantErr  bool
+	}{
+		{"digits at min length", MinLength, AlphabetDigits, false},
+		{"digits at max length", MaxLength, AlphabetDigits, false},
+		{"alphanumeric", 6, AlphabetAlphanumeric, false},
+		{"too short", MinLength - 1, AlphabetDigits, true},
+		{"too long", MaxLength + 1, AlphabetDigits, true},
+		{"unknown alphabet", 6, "hex", true},
+		{"empty alphabet", 6, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Generate(tt.length, tt.alphabet)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Generate(%d, %q) = %q, want an error", tt.length, tt.alphabet, code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate(%d, %q) failed: %v", tt.length, tt.alphabet, err)
+			}
+			if len(code) != tt.length {
+				t.Errorf("len(%q) = %d, want %d", code, len(code), tt.length)
+			}
+			for _, r := range code {
+				if !strings.ContainsRune(alphabets[tt.alphabet], r) {
+					t.Errorf("code %q has %q outside the %s alphabet", code, r, tt.alphabet)
+				}
+			}
+		})
+	}
+}
+
+func TestAlphanumericHasNoLookAlikes(t *testing.T) {
+	for _, r := range "01OIL" {
+		if strings.ContainsRune(alphabets[AlphabetAlphanumeric], r) {
+			t.Errorf("alphanumeric alphabet contains look-alike %q", r)
+		}
+	}
+}
+
+func TestIsValidAlphabet(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{AlphabetDigits, true},
+		{AlphabetAlphanumeric, true},
+		{"Digits", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidAlphabet(tt.name); got != tt.want {
+			t.Errorf("IsValidAlphabet(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
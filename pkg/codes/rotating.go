@@ -0,0 +1,84 @@
+package codes
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"math/big"
+	"time"
+)
+
+// Modes an attendance code can be issued in.
+const (
+	// ModeStatic codes are generated once and stored until they expire.
+	ModeStatic = "static"
+	// ModeRotating codes are derived from a secret and the current time
+	// step, so a value is only valid for a few seconds.
+	ModeRotating = "rotating"
+)
+
+// Limits of the rotation period of rotating codes.
+const (
+	MinStep = 10 * time.Second
+	MaxStep = 30 * time.Second
+)
+
+// NewSecret returns a random secret for deriving rotating codes.
+func NewSecret() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// Counter returns the number of the time step t falls into.
+func Counter(t time.Time, step time.Duration) int64 {
+	return t.Unix() / int64(step/time.Second)
+}
+
+// StepEnd returns the moment the time step containing t ends.
+func StepEnd(t time.Time, step time.Duration) time.Time {
+	seconds := int64(step / time.Second)
+	return time.Unix((Counter(t, step)+1)*seconds, 0)
+}
+
+// Derive returns the rotating code for the given time step counter. Like
+// TOTP (RFC 6238) it is an HMAC of the counter, but the digest is rendered
+// in the requested alphabet and length instead of decimal digits.
+func Derive(secret []byte, counter int64, length int, alphabet string) string {
+	chars, ok := alphabets[alphabet]
+	if !ok {
+		chars = alphabets[AlphabetDigits]
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(msg[:])
+
+	n := new(big.Int).SetBytes(mac.Sum(nil))
+	base := big.NewInt(int64(len(chars)))
+	digit := new(big.Int)
+	code := make([]byte, length)
+	for i := range code {
+		n.DivMod(n, base, digit)
+		code[i] = chars[digit.Int64()]
+	}
+	return string(code)
+}
+
+// Verify reports whether code matches the rotating code of the time step
+// containing now, or of up to skew steps before or after it.
+func Verify(secret []byte, code string, now time.Time, step time.Duration, skew, length int, alphabet string) bool {
+	counter := Counter(now, step)
+	for i := -skew; i <= skew; i++ {
+		expected := Derive(secret, counter+int64(i), length, alphabet)
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package codes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestCounterAndStepEnd(t *testing.T) {
+	step := 30 * time.Second
+	tests := []struct {
+		at      int64
+		counter int64
+		end     int64
+	}{
+		{3000, 100, 3030},
+		{3029, 100, 3030},
+		{3030, 101, 3060},
+		{0, 0, 30},
+	}
+	for _, tt := range tests {
+		at := time.Unix(tt.at, 0)
+		if got := Counter(at, step); got != tt.counter {
+			t.Errorf("Counter(%d) = %d, want %d", tt.at, got, tt.counter)
+		}
+		if got := StepEnd(at, step).Unix(); got != tt.end {
+			t.Errorf("StepEnd(%d) = %d, want %d", tt.at, got, tt.end)
+		}
+	}
+}
+
+func TestDerive(t *testing.T) {
+	tests := []struct {
+		name     string
+		alphabet string
+		chars    string
+	}{
+		{"digits", AlphabetDigits, alphabets[AlphabetDigits]},
+		{"alphanumeric", AlphabetAlphanumeric, alphabets[AlphabetAlphanumeric]},
+		{"unknown falls back to digits", "hex", alphabets[AlphabetDigits]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := Derive(testSecret, 100, 8, tt.alphabet)
+			if len(code) != 8 {
+				t.Fatalf("len(%q) = %d, want 8", code, len(code))
+			}
+			for _, r := range code {
+				if !strings.ContainsRune(tt.chars, r) {
+					t.Errorf("code %q has %q outside the alphabet", code, r)
+				}
+			}
+			if again := Derive(testSecret, 100, 8, tt.alphabet); again != code {
+				t.Errorf("Derive is not deterministic: %q then %q", code, again)
+			}
+		})
+	}
+
+	if Derive(testSecret, 100, 8, AlphabetDigits) == Derive(testSecret, 101, 8, AlphabetDigits) {
+		t.Error("neighbouring steps derived the same code")
+	}
+	if Derive(testSecret, 100, 8, AlphabetDigits) == Derive([]byte("another secret"), 100, 8, AlphabetDigits) {
+		t.Error("different secrets derived the same code")
+	}
+}
+
+func TestVerifyWindow(t *testing.T) {
+	step := 30 * time.Second
+	code := func(counter int64) string { return Derive(testSecret, counter, 6, AlphabetDigits) }
+
+	// Step 100 covers the seconds 3000 to 3029
+	tests := []struct {
+		name string
+		code string
+		now  int64
+		skew int
+		want bool
+	}{
+		{"current step", code(100), 3000, 0, true},
+		{"last second of the step", code(100), 3029, 0, true},
+		{"first second of the next step", code(100), 3030, 0, false},
+		{"previous step within skew", code(100), 3030, 1, true},
+		{"previous step without skew", code(99), 3000, 0, false},
+		{"previous step at the edge of skew", code(99), 3000, 1, true},
+		{"next step at the edge of skew", code(101), 3029, 1, true},
+		{"two steps back beyond skew", code(98), 3000, 1, false},
+		{"two steps ahead beyond skew", code(102), 3029, 1, false},
+		{"two steps back within skew", code(98), 3000, 2, true},
+		{"wrong length", code(100)[:5], 3000, 1, false},
+		{"empty code", "", 3000, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Verify(testSecret, tt.code, time.Unix(tt.now, 0), step, tt.skew, 6, AlphabetDigits)
+			if got != tt.want {
+				t.Errorf("Verify(%q at %d, skew %d) = %v, want %v", tt.code, tt.now, tt.skew, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,34 +4,245 @@ import (
 	"fmt"
 	"log"
 	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"student-attendance-app/pkg/schedule"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func InitDB(url string) (*gorm.DB, error) {
+// InitDB connects to and migrates the database. lateGrace classifies the
+// attendance marks that predate attendance statuses.
+func InitDB(url string, lateGrace time.Duration) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if err := dedupeAttendance(db); err != nil {
+		log.Fatalf("failed to remove duplicate attendance: %v", err)
+	}
+
+	if err := retireStaleCodes(db); err != nil {
+		log.Fatalf("failed to deactivate stale codes: %v", err)
+	}
+
+	// Accounts created before email verification existed count as verified
+	verifyExistingEmails := !db.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+	// Marks made before statuses existed are classified once, see classifyAttendance
+	classifyExisting := !db.Migrator().HasColumn(&models.Attendance{}, "Status")
+
 	// Run migrations
 	if err := db.AutoMigrate(
 		&models.Group{},
+		&models.Role{},
+		&models.RolePermission{},
 		&models.User{},
+		&models.AuthSession{},
+		&models.RefreshToken{},
+		&models.UserToken{},
+		&models.GroupInvite{},
+		&models.GuardianLink{},
+		&models.GuardianInvite{},
+		&models.Term{},
+		&models.Holiday{},
 		&models.Lesson{},
+		&models.LessonTeacher{},
+		&models.LessonDelegation{},
+		&models.LessonSession{},
 		&models.Attendance{},
+		&models.AttendanceChange{},
+		&models.ExcuseRequest{},
 		&models.GeneratedCode{},
+		&models.CodeAttempt{},
+		&models.LoginThrottle{},
 	); err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
 	}
 
+	if verifyExistingEmails {
+		if err := db.Model(&models.User{}).Where("email_verified_at IS NULL").
+			Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			log.Fatalf("failed to mark existing emails as verified: %v", err)
+		}
+	}
+
+	if err := migrateLessonIndex(db); err != nil {
+		log.Fatalf("failed to migrate lesson index: %v", err)
+	}
+
+	if err := backfillSessions(db); err != nil {
+		log.Fatalf("failed to backfill lesson sessions: %v", err)
+	}
+
+	// Classification needs the sessions that backfillSessions links legacy
+	// marks to
+	if classifyExisting {
+		if err := classifyAttendance(db, lateGrace); err != nil {
+			log.Fatalf("failed to classify existing attendance: %v", err)
+		}
+	}
+
 	seedDatabase(db)
 
+	if err := rbac.Seed(db); err != nil {
+		log.Fatalf("failed to seed roles: %v", err)
+	}
+
+	linkLessonTeachers(db)
+
 	return db, nil
 }
 
+// linkLessonTeachers assigns teacher accounts to lessons that only have a
+// free-text Teacher name and no lead teacher yet, matching the name
+// case-insensitively. Lessons whose name matches no account or several
+// accounts are left for an admin.
+func linkLessonTeachers(db *gorm.DB) {
+	var lessons []models.Lesson
+	if err := db.Where("teacher <> '' AND NOT EXISTS (SELECT 1 FROM lesson_teachers lt WHERE lt.lesson_id = lessons.id AND lt.role = ?)", models.LessonTeacherLead).
+		Find(&lessons).Error; err != nil {
+		log.Printf("Failed to load lessons for teacher linking: %v", err)
+		return
+	}
+
+	for _, lesson := range lessons {
+		var teachers []models.User
+		if err := db.Where("id IN (?) AND LOWER(TRIM(name)) = LOWER(TRIM(?))", rbac.UsersWith(db, rbac.LessonsTeach), lesson.Teacher).Find(&teachers).Error; err != nil {
+			log.Printf("Failed to look up teacher %q: %v", lesson.Teacher, err)
+			continue
+		}
+		if len(teachers) != 1 {
+			log.Printf("Lesson %d: %d teacher accounts match %q, not linked.", lesson.ID, len(teachers), lesson.Teacher)
+			continue
+		}
+
+		// A matching assistant is promoted to lead
+		link := models.LessonTeacher{LessonID: lesson.ID, TeacherID: teachers[0].ID, Role: models.LessonTeacherLead}
+		if err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "lesson_id"}, {Name: "teacher_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).Create(&link).Error; err != nil {
+			log.Printf("Failed to link lesson %d to teacher %d: %v", lesson.ID, teachers[0].ID, err)
+		}
+	}
+}
+
+// classifyAttendance marks existing attendance submitted more than lateGrace
+// after its session's start as late, like schedule.Lateness does for new
+// marks. The rest keeps the default status, present.
+func classifyAttendance(db *gorm.DB, lateGrace time.Duration) error {
+	result := db.Exec(`UPDATE attendances SET status = ?,
+			minutes_late = FLOOR(EXTRACT(EPOCH FROM attendances.submitted_at - s.starts_at) / 60)
+		FROM lesson_sessions s
+		WHERE attendances.session_id = s.id AND attendances.submitted_at > s.starts_at + ? * INTERVAL '1 second'`,
+		models.AttendanceLate, int64(lateGrace/time.Second))
+	if result.RowsAffected > 0 {
+		log.Printf("Classified %d existing attendance records as late.", result.RowsAffected)
+	}
+	return result.Error
+}
+
+// migrateLessonIndex replaces the older name/day/time unique indexes with one
+// that also includes the week parity and the term, so an odd-week and an
+// even-week lesson can share a slot and the slot can be reused in the next
+// term. Unbound lessons are treated as term 0 so they still cannot be
+// duplicated.
+func migrateLessonIndex(db *gorm.DB) error {
+	for _, old := range []string{"idx_lesson_name_day_time", "idx_lesson_name_day_time_term"} {
+		if err := db.Exec("DROP INDEX IF EXISTS " + old).Error; err != nil {
+			return err
+		}
+	}
+	return db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_lesson_name_day_time_parity_term ON lessons (name, day, time, week_parity, COALESCE(term_id, 0))").Error
+}
+
+// dedupeAttendance removes repeated marks of a student in the same session,
+// keeping the earliest one, so that idx_attendance_session_student can be
+// created on databases that predate it.
+func dedupeAttendance(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Attendance{}, "session_id") {
+		return nil
+	}
+	result := db.Exec(`DELETE FROM attendances a USING attendances b
+		WHERE a.session_id = b.session_id AND a.student_id = b.student_id AND a.id > b.id`)
+	if result.RowsAffected > 0 {
+		log.Printf("Removed %d duplicate attendance records.", result.RowsAffected)
+	}
+	return result.Error
+}
+
+// retireStaleCodes deactivates expired codes, and all but the newest of any
+// codes sharing a value, so that idx_generated_codes_active_static_code can
+// be created on databases that predate it.
+func retireStaleCodes(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.GeneratedCode{}) {
+		return nil
+	}
+	// Superseded by idx_generated_codes_active_static_code, which leaves
+	// rotating codes out
+	if err := db.Exec("DROP INDEX IF EXISTS idx_generated_codes_active_code").Error; err != nil {
+		return err
+	}
+	return db.Exec(`UPDATE generated_codes SET is_active = false
+		WHERE is_active AND (expires_at <= now() OR (code <> '' AND id NOT IN (
+			SELECT MAX(id) FROM generated_codes WHERE is_active AND code <> '' GROUP BY code)))`).Error
+}
+
+// backfillSessions attaches attendance records and codes created before
+// lessons had dated sessions to the session inferred from their timestamps.
+func backfillSessions(db *gorm.DB) error {
+	var attendance []models.Attendance
+	if err := db.Preload("Lesson").Where("session_id IS NULL").Order("submitted_at, id").Find(&attendance).Error; err != nil {
+		return err
+	}
+	for _, a := range attendance {
+		session, err := schedule.EnsureSession(db, a.Lesson, a.SubmittedAt.Local())
+		if err != nil {
+			return err
+		}
+
+		// A student may have marked the same session several times before
+		// duplicates were rejected; keep only the earliest mark.
+		var marked int64
+		if err := db.Model(&models.Attendance{}).Where("session_id = ? AND student_id = ?", session.ID, a.StudentID).Count(&marked).Error; err != nil {
+			return err
+		}
+		if marked > 0 {
+			if err := db.Delete(&models.Attendance{}, a.ID).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := db.Model(&models.Attendance{}).Where("id = ?", a.ID).Update("session_id", session.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	var codes []models.GeneratedCode
+	if err := db.Preload("Lesson").Where("session_id IS NULL").Find(&codes).Error; err != nil {
+		return err
+	}
+	for _, code := range codes {
+		session, err := schedule.EnsureSession(db, code.Lesson, code.CreatedAt.Local())
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&models.GeneratedCode{}).Where("id = ?", code.ID).Update("session_id", session.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(attendance) > 0 || len(codes) > 0 {
+		log.Printf("Backfilled sessions for %d attendance records and %d codes.", len(attendance), len(codes))
+	}
+	return nil
+}
+
 func seedDatabase(db *gorm.DB) {
 	// Seed Groups if they don't exist
 	groups := []models.Group{
@@ -85,13 +296,15 @@ func seedUsers(db *gorm.DB) {
 				continue
 			}
 
+			verifiedAt := time.Now()
 			user := models.User{
-				Identifier: u.Identifier,
-				Password:   string(hashedPassword),
-				Name:       u.Name,
-				Email:      u.Email,
-				Role:       u.Role,
-				GroupID:    &group.ID,
+				Identifier:      u.Identifier,
+				Password:        string(hashedPassword),
+				Name:            u.Name,
+				Email:           u.Email,
+				Role:            u.Role,
+				GroupID:         &group.ID,
+				EmailVerifiedAt: &verifiedAt,
 			}
 			if err := db.Create(&user).Error; err != nil {
 				log.Printf("Failed to create user %s: %v", u.Identifier, err)
@@ -121,8 +334,19 @@ func seedLessons(db *gorm.DB) {
 		{Name: "Информатика", Day: "Пятница", Time: "10:45-12:15", Teacher: "Сергей Николаевич", Room: "404", GroupID: &groupC.ID},
 	}
 
-	for _, lesson := range lessons {
-		db.FirstOrCreate(&lesson, models.Lesson{Name: lesson.Name, Day: lesson.Day, Time: lesson.Time})
+	// Let the test teacher run every seeded lesson. The link is only made
+	// when the lesson is created, so an admin can remove it for good.
+	var teacher models.User
+	hasTeacher := db.First(&teacher, "identifier = ?", "teacher001").Error == nil
+	for i := range lessons {
+		result := db.FirstOrCreate(&lessons[i], models.Lesson{Name: lessons[i].Name, Day: lessons[i].Day, Time: lessons[i].Time})
+		if result.Error != nil || result.RowsAffected == 0 || !hasTeacher {
+			continue
+		}
+		link := models.LessonTeacher{LessonID: lessons[i].ID, TeacherID: teacher.ID, Role: models.LessonTeacherAssistant}
+		if err := db.Create(&link).Error; err != nil {
+			log.Printf("Failed to link lesson %d to teacher %d: %v", lessons[i].ID, teacher.ID, err)
+		}
 	}
 	log.Println("Lessons seeded.")
 }
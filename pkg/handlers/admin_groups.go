@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GroupRequest struct {
+	Name string `json:"name" binding:"required" example:"Group D"`
+}
+
+// AdminCreateGroup godoc
+// @Summary Создать группу (Админ)
+// @Description Создает новую студенческую группу.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param group body GroupRequest true "Данные группы"
+// @Success 200 {object} models.Group "Созданная группа"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 409 {object} map[string]interface{} "Группа с таким названием уже существует"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/groups [post]
+func AdminCreateGroup(c *gin.Context, db *gorm.DB) {
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Group name must not be empty"})
+		return
+	}
+
+	group := models.Group{Name: name}
+	if err := db.Create(&group).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Group with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// AdminUpdateGroup godoc
+// @Summary Переименовать группу (Админ)
+// @Description Изменяет название существующей группы.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Param group body GroupRequest true "Данные группы"
+// @Success 200 {object} models.Group "Обновленная группа"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 409 {object} map[string]interface{} "Группа с таким названием уже существует"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/groups/{id} [put]
+func AdminUpdateGroup(c *gin.Context, db *gorm.DB) {
+	var group models.Group
+	if err := db.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Group name must not be empty"})
+		return
+	}
+
+	group.Name = name
+	if err := db.Save(&group).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Group with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// AdminDeleteGroup godoc
+// @Summary Удалить группу (Админ)
+// @Description Удаляет группу. Если в группе есть пользователи или занятия, удаление отклоняется, пока не передан параметр cascade=true, который открепляет их от группы. Коды приглашения группы удаляются.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Param cascade query bool false "Открепить пользователей и занятия перед удалением"
+// @Success 200 {object} map[string]interface{} "Группа успешно удалена"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 409 {object} map[string]interface{} "Группа используется пользователями или занятиями"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/groups/{id} [delete]
+func AdminDeleteGroup(c *gin.Context, db *gorm.DB) {
+	var group models.Group
+	if err := db.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var userCount, lessonCount int64
+	if err := db.Model(&models.User{}).Where("group_id = ?", group.ID).Count(&userCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+	if err := db.Model(&models.Lesson{}).Where("group_id = ?", group.ID).Count(&lessonCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+
+	if (userCount > 0 || lessonCount > 0) && c.Query("cascade") != "true" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Group is still referenced by users or lessons",
+			"users":   userCount,
+			"lessons": lessonCount,
+		})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("group_id = ?", group.ID).Update("group_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Lesson{}).Where("group_id = ?", group.ID).Update("group_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.GroupInvite{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// AdminGetGroupMembers godoc
+// @Summary Получить участников группы (Админ)
+// @Description Возвращает список пользователей, входящих в группу.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Success 200 {array} models.User "Список пользователей"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/groups/{id}/members [get]
+func AdminGetGroupMembers(c *gin.Context, db *gorm.DB) {
+	var group models.Group
+	if err := db.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var users []models.User
+	if err := db.Where("group_id = ?", group.ID).Order("name").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve group members"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// AdminGetGroupLessons godoc
+// @Summary Получить занятия группы (Админ)
+// @Description Возвращает расписание занятий группы.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Success 200 {array} models.Lesson "Список занятий"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/groups/{id}/lessons [get]
+func AdminGetGroupLessons(c *gin.Context, db *gorm.DB) {
+	var group models.Group
+	if err := db.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var lessons []models.Lesson
+	if err := db.Where("group_id = ?", group.ID).Order("day, time").Find(&lessons).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve group lessons"})
+		return
+	}
+	c.JSON(http.StatusOK, lessons)
+}
@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/codes"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"student-attendance-app/pkg/schedule"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LessonRequest struct {
+	Name       string  `json:"name" binding:"required" example:"Алгебра"`
+	Day        string  `json:"day" binding:"required" example:"Понедельник"`
+	Time       string  `json:"time" binding:"required" example:"09:00-10:30"`
+	WeekParity string  `json:"week_parity" example:"every"`         // 'every' (default), 'odd' or 'even'
+	Teacher    *string `json:"teacher" example:"Анна Владимировна"` // Defaults to the names of teacher_ids; kept on update without teacher or teacher_ids
+	Room       string  `json:"room" example:"101"`
+	GroupID    *uint   `json:"group_id" example:"1"`
+	TermID     *uint   `json:"term_id" example:"1"`
+	// Teacher accounts leading and assisting the lesson. When both are
+	// omitted the current assignments are kept.
+	TeacherIDs   []uint `json:"teacher_ids" example:"2"`
+	AssistantIDs []uint `json:"assistant_ids" example:"5"`
+	// Overrides of the server-wide attendance code settings, null to use them
+	CodeLength     *int    `json:"code_length" example:"6"`
+	CodeAlphabet   *string `json:"code_alphabet" example:"alphanumeric"`
+	CodeTTLSeconds *int    `json:"code_ttl_seconds" example:"600"`
+}
+
+// validate checks the day, time, group, term, code settings and teachers of
+// the request and returns a message suitable for a 400 response.
+func (req *LessonRequest) validate(db *gorm.DB) string {
+	if !schedule.IsValidDay(req.Day) {
+		return "Invalid day, expected one of the weekday names"
+	}
+	if _, _, err := schedule.ParseTimeRange(req.Time); err != nil {
+		return "Invalid time: " + err.Error()
+	}
+	if req.WeekParity == "" {
+		req.WeekParity = models.WeekEvery
+	}
+	if !schedule.IsValidWeekParity(req.WeekParity) {
+		return "Invalid week_parity, expected every, odd or even"
+	}
+	if req.WeekParity != models.WeekEvery && req.TermID == nil {
+		return "Odd or even week lessons need a term_id to count weeks from"
+	}
+	if req.GroupID != nil {
+		var group models.Group
+		if err := db.First(&group, *req.GroupID).Error; err != nil {
+			return "Invalid group ID"
+		}
+	}
+	if req.TermID != nil {
+		var term models.Term
+		if err := db.First(&term, *req.TermID).Error; err != nil {
+			return "Invalid term ID"
+		}
+	}
+	if req.CodeLength != nil && (*req.CodeLength < codes.MinLength || *req.CodeLength > codes.MaxLength) {
+		return fmt.Sprintf("Invalid code_length, expected %d to %d", codes.MinLength, codes.MaxLength)
+	}
+	if req.CodeAlphabet != nil && !codes.IsValidAlphabet(*req.CodeAlphabet) {
+		return "Invalid code_alphabet, expected digits or alphanumeric"
+	}
+	if req.CodeTTLSeconds != nil && *req.CodeTTLSeconds <= 0 {
+		return "Invalid code_ttl_seconds, expected a positive number"
+	}
+	if req.TeacherIDs != nil || req.AssistantIDs != nil {
+		return req.validateTeachers(db)
+	}
+	return ""
+}
+
+func (req *LessonRequest) validateTeachers(db *gorm.DB) string {
+	ids := append(append([]uint{}, req.TeacherIDs...), req.AssistantIDs...)
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return "A teacher can only be assigned to a lesson once"
+		}
+		seen[id] = true
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+
+	var teachers []models.User
+	if err := db.Where("id IN ? AND id IN (?)", ids, rbac.UsersWith(db, rbac.LessonsTeach)).Find(&teachers).Error; err != nil || len(teachers) != len(ids) {
+		return "Invalid teacher ID"
+	}
+
+	if req.Teacher == nil || strings.TrimSpace(*req.Teacher) == "" {
+		names := make(map[uint]string, len(teachers))
+		for _, t := range teachers {
+			names[t.ID] = t.Name
+		}
+		leads := make([]string, 0, len(req.TeacherIDs))
+		for _, id := range req.TeacherIDs {
+			leads = append(leads, names[id])
+		}
+		teacher := strings.Join(leads, ", ")
+		req.Teacher = &teacher
+	}
+	return ""
+}
+
+func (req *LessonRequest) apply(lesson *models.Lesson) {
+	lesson.Name = req.Name
+	lesson.Day = req.Day
+	lesson.Time = req.Time
+	lesson.WeekParity = req.WeekParity
+	if req.Teacher != nil {
+		lesson.Teacher = *req.Teacher
+	}
+	lesson.Room = req.Room
+	lesson.GroupID = req.GroupID
+	lesson.TermID = req.TermID
+	lesson.CodeLength = req.CodeLength
+	lesson.CodeAlphabet = req.CodeAlphabet
+	lesson.CodeTTLSeconds = req.CodeTTLSeconds
+}
+
+// save stores the lesson and, when the request lists teacher accounts,
+// replaces its teacher assignments.
+func (req *LessonRequest) save(db *gorm.DB, lesson *models.Lesson) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Teachers").Save(lesson).Error; err != nil {
+			return err
+		}
+		if req.TeacherIDs == nil && req.AssistantIDs == nil {
+			return nil
+		}
+
+		if err := tx.Where("lesson_id = ?", lesson.ID).Delete(&models.LessonTeacher{}).Error; err != nil {
+			return err
+		}
+		var links []models.LessonTeacher
+		for _, id := range req.TeacherIDs {
+			links = append(links, models.LessonTeacher{LessonID: lesson.ID, TeacherID: id, Role: models.LessonTeacherLead})
+		}
+		for _, id := range req.AssistantIDs {
+			links = append(links, models.LessonTeacher{LessonID: lesson.ID, TeacherID: id, Role: models.LessonTeacherAssistant})
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// AdminGetLessons godoc
+// @Summary Получить занятия (Админ)
+// @Description Возвращает список занятий с фильтрацией по группе, преподавателю, дню недели и семестру.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param group_id query int false "ID Группы"
+// @Param teacher query string false "Преподаватель (поиск по подстроке)"
+// @Param teacher_id query int false "ID Преподавателя"
+// @Param day query string false "День недели"
+// @Param term_id query int false "ID Семестра"
+// @Success 200 {array} models.Lesson "Список занятий"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/lessons [get]
+func AdminGetLessons(c *gin.Context, db *gorm.DB) {
+	query := db.Preload("Group").Preload("Term").Preload("Teachers.Teacher")
+	if groupID := c.Query("group_id"); groupID != "" {
+		query = query.Where("group_id = ?", groupID)
+	}
+	if teacher := c.Query("teacher"); teacher != "" {
+		query = query.Where("teacher ILIKE ?", "%"+teacher+"%")
+	}
+	if teacherID := c.Query("teacher_id"); teacherID != "" {
+		query = query.Where("id IN (?)", db.Model(&models.LessonTeacher{}).Select("lesson_id").Where("teacher_id = ?", teacherID))
+	}
+	if day := c.Query("day"); day != "" {
+		query = query.Where("day = ?", day)
+	}
+	if termID := c.Query("term_id"); termID != "" {
+		query = query.Where("term_id = ?", termID)
+	}
+
+	var lessons []models.Lesson
+	if err := query.Order("day, time").Find(&lessons).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve lessons"})
+		return
+	}
+	c.JSON(http.StatusOK, lessons)
+}
+
+// AdminCreateLesson godoc
+// @Summary Создать занятие (Админ)
+// @Description Создает новое занятие в расписании.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param lesson body LessonRequest true "Данные занятия"
+// @Success 200 {object} models.Lesson "Созданное занятие"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 409 {object} map[string]interface{} "Занятие с таким названием, днем и временем уже существует в семестре"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/lessons [post]
+func AdminCreateLesson(c *gin.Context, db *gorm.DB) {
+	var req LessonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(db); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var lesson models.Lesson
+	req.apply(&lesson)
+	if err := req.save(db, &lesson); err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Lesson with this name, day and time already exists in this term"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create lesson"})
+		return
+	}
+
+	db.Preload("Group").Preload("Term").Preload("Teachers.Teacher").First(&lesson, lesson.ID)
+	c.JSON(http.StatusOK, lesson)
+}
+
+// AdminUpdateLesson godoc
+// @Summary Обновить занятие (Админ)
+// @Description Обновляет данные существующего занятия.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Занятия"
+// @Param lesson body LessonRequest true "Данные занятия"
+// @Success 200 {object} models.Lesson "Обновленное занятие"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Занятие не найдено"
+// @Failure 409 {object} map[string]interface{} "Занятие с таким названием, днем и временем уже существует в семестре"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/lessons/{id} [put]
+func AdminUpdateLesson(c *gin.Context, db *gorm.DB) {
+	var lesson models.Lesson
+	if err := db.First(&lesson, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+
+	var req LessonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(db); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	req.apply(&lesson)
+	if err := req.save(db, &lesson); err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Lesson with this name, day and time already exists in this term"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update lesson"})
+		return
+	}
+
+	db.Preload("Group").Preload("Term").Preload("Teachers.Teacher").First(&lesson, lesson.ID)
+	c.JSON(http.StatusOK, lesson)
+}
+
+// AdminDeleteLesson godoc
+// @Summary Удалить занятие (Админ)
+// @Description Удаляет занятие вместе с его кодами, сессиями и назначениями преподавателей. Занятия с записями о посещаемости удалить нельзя.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Занятия"
+// @Success 200 {object} map[string]interface{} "Занятие успешно удалено"
+// @Failure 404 {object} map[string]interface{} "Занятие не найдено"
+// @Failure 409 {object} map[string]interface{} "У занятия есть записи о посещаемости"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/lessons/{id} [delete]
+func AdminDeleteLesson(c *gin.Context, db *gorm.DB) {
+	var lesson models.Lesson
+	if err := db.First(&lesson, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+
+	var attendanceCount int64
+	if err := db.Model(&models.Attendance{}).Where("lesson_id = ?", lesson.ID).Count(&attendanceCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete lesson"})
+		return
+	}
+	if attendanceCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Lesson has attendance records and cannot be deleted"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("lesson_id = ?", lesson.ID).Delete(&models.GeneratedCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("session_id IN (?)", tx.Model(&models.LessonSession{}).Select("id").Where("lesson_id = ?", lesson.ID)).
+			Delete(&models.CodeAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("lesson_id = ?", lesson.ID).Delete(&models.AttendanceChange{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("lesson_id = ?", lesson.ID).Delete(&models.LessonSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("lesson_id = ?", lesson.ID).Delete(&models.LessonTeacher{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("lesson_id = ?", lesson.ID).Delete(&models.LessonDelegation{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&lesson).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete lesson"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Lesson deleted successfully"})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"student-attendance-app/pkg/throttle"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminGetLockouts godoc
+// @Summary Получить блокировки входа (Админ)
+// @Description Возвращает счетчики неудачных входов по логинам (identifier:...) и IP-адресам (ip:...), включая действующие блокировки (locked_until в будущем).
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} throttle.Entry "Список счетчиков"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/lockouts [get]
+func AdminGetLockouts(c *gin.Context, throttler *throttle.Throttler) {
+	entries, err := throttler.Entries(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve lockouts"})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// AdminDeleteLockout godoc
+// @Summary Снять блокировку входа (Админ)
+// @Description Сбрасывает счетчик неудачных входов и блокировку для ключа, например identifier:student001 или ip:10.0.0.5.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param key path string true "Ключ счетчика"
+// @Success 200 {object} map[string]interface{} "Блокировка снята"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/lockouts/{key} [delete]
+func AdminDeleteLockout(c *gin.Context, throttler *throttle.Throttler) {
+	if err := throttler.Reset(c.Param("key")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear lockout"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout cleared successfully"})
+}
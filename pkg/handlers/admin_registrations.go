@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"student-attendance-app/pkg/mailer"
+	"student-attendance-app/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminGetRegistrations godoc
+// @Summary Получить очередь регистраций (Админ)
+// @Description Возвращает зарегистрировавшихся студентов с указанным статусом, по умолчанию ожидающих одобрения (pending). Старые заявки - первыми.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Статус: pending (по умолчанию) или rejected"
+// @Success 200 {array} models.User "Список пользователей"
+// @Failure 400 {object} map[string]interface{} "Неверный статус"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/registrations [get]
+func AdminGetRegistrations(c *gin.Context, db *gorm.DB) {
+	status := c.DefaultQuery("status", models.UserPending)
+	if status != models.UserPending && status != models.UserRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected pending or rejected"})
+		return
+	}
+
+	var users []models.User
+	if err := db.Preload("Group").Where("status = ?", status).Order("created_at").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve registrations"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// setRegistrationStatus moves a pending (or rejected) registration to status
+// and lets the student know by email.
+func setRegistrationStatus(c *gin.Context, db *gorm.DB, m mailer.Mailer, status string) {
+	var user models.User
+	if err := db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.Status == status {
+		c.JSON(http.StatusOK, user)
+		return
+	}
+	if user.Status == models.UserActive {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is already active"})
+		return
+	}
+
+	if err := db.Model(&user).Update("status", status).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update registration"})
+		return
+	}
+
+	msg := mailer.Message{To: user.Email, Subject: "Регистрация одобрена",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nАдминистратор одобрил вашу регистрацию, теперь вы можете войти в систему учета посещаемости.\n", user.Name)}
+	if status == models.UserRejected {
+		msg.Subject = "Регистрация отклонена"
+		msg.Body = fmt.Sprintf("Здравствуйте, %s!\n\nАдминистратор отклонил вашу регистрацию в системе учета посещаемости.\n", user.Name)
+	}
+	sendMail(m, msg)
+
+	c.JSON(http.StatusOK, user)
+}
+
+// AdminApproveRegistration godoc
+// @Summary Одобрить регистрацию (Админ)
+// @Description Активирует аккаунт студента, ожидающего одобрения (или ранее отклоненного), и сообщает ему об этом по email.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Пользователя"
+// @Success 200 {object} models.User "Одобренный пользователь"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 409 {object} map[string]interface{} "Пользователь уже активен"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/registrations/{id}/approve [post]
+func AdminApproveRegistration(c *gin.Context, db *gorm.DB, m mailer.Mailer) {
+	setRegistrationStatus(c, db, m, models.UserActive)
+}
+
+// AdminRejectRegistration godoc
+// @Summary Отклонить регистрацию (Админ)
+// @Description Отклоняет регистрацию студента, ожидающего одобрения. Отклоненный пользователь не может войти.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Пользователя"
+// @Success 200 {object} models.User "Отклоненный пользователь"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 409 {object} map[string]interface{} "Пользователь уже активен"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/registrations/{id}/reject [post]
+func AdminRejectRegistration(c *gin.Context, db *gorm.DB, m mailer.Mailer) {
+	setRegistrationStatus(c, db, m, models.UserRejected)
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required" example:"curator"`
+	Description string   `json:"description" example:"Куратор группы"`
+	Permissions []string `json:"permissions" example:"attendance.read.group"`
+}
+
+type UserRolesRequest struct {
+	RoleIDs []uint `json:"role_ids" binding:"required" example:"1,2"`
+}
+
+// RoleResponse is a role with its permissions listed by name.
+type RoleResponse struct {
+	models.Role
+	Permissions []string `json:"permissions"`
+}
+
+func roleResponses(roles []models.Role) []RoleResponse {
+	responses := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, 0, len(role.Permissions))
+		for _, p := range role.Permissions {
+			permissions = append(permissions, p.Permission)
+		}
+		sort.Strings(permissions)
+		responses = append(responses, RoleResponse{Role: role, Permissions: permissions})
+	}
+	return responses
+}
+
+// normalizePermissions rejects unknown permissions and drops duplicates.
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !rbac.IsKnown(permission) {
+			return nil, fmt.Errorf("unknown permission %q", permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			normalized = append(normalized, permission)
+		}
+	}
+	return normalized, nil
+}
+
+// setRolePermissions replaces all permissions of the role.
+func setRolePermissions(tx *gorm.DB, roleID uint, permissions []string) error {
+	if err := tx.Where("role_id = ?", roleID).Delete(&models.RolePermission{}).Error; err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if err := tx.Create(&models.RolePermission{RoleID: roleID, Permission: permission}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdminGetPermissions godoc
+// @Summary Получить список прав (Админ)
+// @Description Возвращает все права, которые можно включить в роль.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} rbac.Permission "Список прав"
+// @Router /api/admin/permissions [get]
+func AdminGetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, rbac.All())
+}
+
+// AdminGetRoles godoc
+// @Summary Получить роли (Админ)
+// @Description Возвращает все роли с их правами.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} RoleResponse "Список ролей"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/roles [get]
+func AdminGetRoles(c *gin.Context, db *gorm.DB) {
+	var roles []models.Role
+	if err := db.Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve roles"})
+		return
+	}
+	c.JSON(http.StatusOK, roleResponses(roles))
+}
+
+// AdminCreateRole godoc
+// @Summary Создать роль (Админ)
+// @Description Создает роль с указанным набором прав.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param role body RoleRequest true "Данные роли"
+// @Success 200 {object} RoleResponse "Созданная роль"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или неизвестное право"
+// @Failure 409 {object} map[string]interface{} "Роль с таким названием уже существует"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/roles [post]
+func AdminCreateRole(c *gin.Context, db *gorm.DB) {
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role name is required"})
+		return
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role := models.Role{Name: name, Description: req.Description}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return setRolePermissions(tx, role.ID, permissions)
+	})
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Role already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+		return
+	}
+
+	sort.Strings(permissions)
+	c.JSON(http.StatusOK, RoleResponse{Role: role, Permissions: permissions})
+}
+
+// AdminUpdateRole godoc
+// @Summary Обновить роль (Админ)
+// @Description Заменяет описание и набор прав роли. Изменения применяются ко всем пользователям роли сразу. Встроенные роли нельзя переименовать, а роль admin всегда сохраняет право roles.manage.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Роли"
+// @Param role body RoleRequest true "Данные роли"
+// @Success 200 {object} RoleResponse "Обновленная роль"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или неизвестное право"
+// @Failure 404 {object} map[string]interface{} "Роль не найдена"
+// @Failure 409 {object} map[string]interface{} "Роль с таким названием уже существует"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/roles/{id} [put]
+func AdminUpdateRole(c *gin.Context, db *gorm.DB) {
+	var role models.Role
+	if err := db.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role name is required"})
+		return
+	}
+	// Built-in roles are matched by name against users' primary role
+	if role.System && name != role.Name {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be renamed"})
+		return
+	}
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if role.Name == rbac.RoleAdmin && !containsString(permissions, rbac.RolesManage) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The admin role must keep roles.manage"})
+		return
+	}
+
+	role.Name = name
+	role.Description = req.Description
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&role).Error; err != nil {
+			return err
+		}
+		return setRolePermissions(tx, role.ID, permissions)
+	})
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Role already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	sort.Strings(permissions)
+	c.JSON(http.StatusOK, RoleResponse{Role: role, Permissions: permissions})
+}
+
+// AdminDeleteRole godoc
+// @Summary Удалить роль (Админ)
+// @Description Удаляет роль, которая не назначена ни одному пользователю. Встроенные роли удалить нельзя.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Роли"
+// @Success 200 {object} map[string]interface{} "Роль удалена"
+// @Failure 400 {object} map[string]interface{} "Встроенную роль нельзя удалить"
+// @Failure 404 {object} map[string]interface{} "Роль не найдена"
+// @Failure 409 {object} map[string]interface{} "Роль назначена пользователям"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/roles/{id} [delete]
+func AdminDeleteRole(c *gin.Context, db *gorm.DB) {
+	var role models.Role
+	if err := db.First(&role, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return
+	}
+	if role.System {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	var assigned int64
+	if err := db.Table("user_roles").Where("role_id = ?", role.ID).Count(&assigned).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+	if assigned > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role is assigned to users"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+// AdminGetUserRoles godoc
+// @Summary Получить роли пользователя (Админ)
+// @Description Возвращает роли, назначенные пользователю.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Пользователя"
+// @Success 200 {array} RoleResponse "Роли пользователя"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id}/roles [get]
+func AdminGetUserRoles(c *gin.Context, db *gorm.DB) {
+	var user models.User
+	if err := db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var roles []models.Role
+	assigned := db.Session(&gorm.Session{NewDB: true}).Table("user_roles").Select("role_id").Where("user_id = ?", user.ID)
+	if err := db.Preload("Permissions").Where("id IN (?)", assigned).Order("id").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve roles"})
+		return
+	}
+	c.JSON(http.StatusOK, roleResponses(roles))
+}
+
+// AdminSetUserRoles godoc
+// @Summary Назначить роли пользователю (Админ)
+// @Description Заменяет набор ролей пользователя. Права пользователя складываются из прав всех его ролей и меняются сразу, без повторного входа. Нельзя лишить себя права roles.manage.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Пользователя"
+// @Param roles body UserRolesRequest true "ID ролей"
+// @Success 200 {array} RoleResponse "Роли пользователя"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или неизвестная роль"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id}/roles [put]
+func AdminSetUserRoles(c *gin.Context, db *gorm.DB) {
+	var user models.User
+	if err := db.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req UserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	roles := []models.Role{}
+	if len(req.RoleIDs) > 0 {
+		if err := db.Preload("Permissions").Where("id IN ?", req.RoleIDs).Order("id").Find(&roles).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve roles"})
+			return
+		}
+	}
+	found := make(map[uint]bool, len(roles))
+	for _, role := range roles {
+		found[role.ID] = true
+	}
+	for _, id := range req.RoleIDs {
+		if !found[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Role %d not found", id)})
+			return
+		}
+	}
+
+	// Guard against admins locking themselves out of role management
+	if user.ID == currentUserID(c) {
+		keeps := false
+		for _, response := range roleResponses(roles) {
+			keeps = keeps || containsString(response.Permissions, rbac.RolesManage)
+		}
+		if !keeps {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove roles.manage from yourself"})
+			return
+		}
+	}
+
+	// Only the user_roles links are written, never the roles themselves
+	if err := db.Model(&user).Omit("Roles.*").Association("Roles").Replace(roles); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign roles"})
+		return
+	}
+	c.JSON(http.StatusOK, roleResponses(roles))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/schedule"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TermRequest struct {
+	Name      string `json:"name" binding:"required" example:"Осенний семестр 2025"`
+	StartDate string `json:"start_date" binding:"required" example:"2025-09-01"`
+	EndDate   string `json:"end_date" binding:"required" example:"2025-12-28"`
+}
+
+type HolidayRequest struct {
+	Date string `json:"date" binding:"required" example:"2025-11-04"`
+	Name string `json:"name" example:"День народного единства"`
+}
+
+// parse validates the request and fills term. It returns a message suitable
+// for a 400 response.
+func (req *TermRequest) parse(term *models.Term) string {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "Term name must not be empty"
+	}
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return "Invalid start_date, expected YYYY-MM-DD"
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return "Invalid end_date, expected YYYY-MM-DD"
+	}
+	if end.Before(start) {
+		return "Term must end after it starts"
+	}
+
+	term.Name = name
+	term.StartDate = start
+	term.EndDate = end
+	return ""
+}
+
+// termOverlaps reports whether another term shares at least one day with term.
+func termOverlaps(db *gorm.DB, term models.Term) (bool, error) {
+	var count int64
+	err := db.Model(&models.Term{}).
+		Where("id <> ? AND start_date <= ? AND end_date >= ?", term.ID, term.EndDate, term.StartDate).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetCurrentTerm godoc
+// @Summary Получить текущий семестр
+// @Description Возвращает семестр, который идет сегодня, вместе с его праздничными днями.
+// @Tags lessons
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} models.Term "Текущий семестр"
+// @Failure 404 {object} map[string]interface{} "Сейчас нет активного семестра"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/terms/current [get]
+func GetCurrentTerm(c *gin.Context, db *gorm.DB) {
+	term, err := schedule.CurrentTerm(db, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to determine current term"})
+		return
+	}
+	if term == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No term in progress"})
+		return
+	}
+	db.Where("term_id = ?", term.ID).Order("date").Find(&term.Holidays)
+	c.JSON(http.StatusOK, term)
+}
+
+// AdminGetTerms godoc
+// @Summary Получить семестры (Админ)
+// @Description Возвращает список всех семестров с праздничными днями.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} models.Term "Список семестров"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/terms [get]
+func AdminGetTerms(c *gin.Context, db *gorm.DB) {
+	var terms []models.Term
+	if err := db.Preload("Holidays", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date")
+	}).Order("start_date desc").Find(&terms).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve terms"})
+		return
+	}
+	c.JSON(http.StatusOK, terms)
+}
+
+// AdminCreateTerm godoc
+// @Summary Создать семестр (Админ)
+// @Description Создает новый семестр. Семестры не могут пересекаться по датам.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param term body TermRequest true "Данные семестра"
+// @Success 200 {object} models.Term "Созданный семестр"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 409 {object} map[string]interface{} "Семестр с таким названием уже существует или пересекается с другим"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/terms [post]
+func AdminCreateTerm(c *gin.Context, db *gorm.DB) {
+	var req TermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var term models.Term
+	if msg := req.parse(&term); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	saveTerm(c, db, &term)
+}
+
+// AdminUpdateTerm godoc
+// @Summary Обновить семестр (Админ)
+// @Description Изменяет название и даты семестра.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Семестра"
+// @Param term body TermRequest true "Данные семестра"
+// @Success 200 {object} models.Term "Обновленный семестр"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Семестр не найден"
+// @Failure 409 {object} map[string]interface{} "Семестр с таким названием уже существует или пересекается с другим"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/terms/{id} [put]
+func AdminUpdateTerm(c *gin.Context, db *gorm.DB) {
+	var term models.Term
+	if err := db.First(&term, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Term not found"})
+		return
+	}
+
+	var req TermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.parse(&term); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	saveTerm(c, db, &term)
+}
+
+func saveTerm(c *gin.Context, db *gorm.DB, term *models.Term) {
+	overlaps, err := termOverlaps(db, *term)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save term"})
+		return
+	}
+	if overlaps {
+		c.JSON(http.StatusConflict, gin.H{"error": "Term overlaps with another term"})
+		return
+	}
+
+	if err := db.Save(term).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Term with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save term"})
+		return
+	}
+	c.JSON(http.StatusOK, term)
+}
+
+// AdminDeleteTerm godoc
+// @Summary Удалить семестр (Админ)
+// @Description Удаляет семестр вместе с его праздничными днями. Семестр, к которому привязаны занятия, удалить нельзя.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Семестра"
+// @Success 200 {object} map[string]interface{} "Семестр успешно удален"
+// @Failure 404 {object} map[string]interface{} "Семестр не найден"
+// @Failure 409 {object} map[string]interface{} "К семестру привязаны занятия"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/terms/{id} [delete]
+func AdminDeleteTerm(c *gin.Context, db *gorm.DB) {
+	var term models.Term
+	if err := db.First(&term, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Term not found"})
+		return
+	}
+
+	var lessonCount int64
+	if err := db.Model(&models.Lesson{}).Where("term_id = ?", term.ID).Count(&lessonCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete term"})
+		return
+	}
+	if lessonCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Term still has lessons", "lessons": lessonCount})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("term_id = ?", term.ID).Delete(&models.Holiday{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&term).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete term"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Term deleted successfully"})
+}
+
+// AdminCreateHoliday godoc
+// @Summary Добавить праздничный день (Админ)
+// @Description Добавляет нерабочий день в семестр. В этот день занятия семестра не проводятся.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Семестра"
+// @Param holiday body HolidayRequest true "Данные праздничного дня"
+// @Success 200 {object} models.Holiday "Созданный праздничный день"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Семестр не найден"
+// @Failure 409 {object} map[string]interface{} "Этот день уже отмечен как праздничный"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/terms/{id}/holidays [post]
+func AdminCreateHoliday(c *gin.Context, db *gorm.DB) {
+	var term models.Term
+	if err := db.First(&term, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Term not found"})
+		return
+	}
+
+	var req HolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+	if date.Before(schedule.DateOf(term.StartDate)) || date.After(schedule.DateOf(term.EndDate)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Holiday must fall within the term"})
+		return
+	}
+
+	holiday := models.Holiday{TermID: term.ID, Date: date, Name: strings.TrimSpace(req.Name)}
+	if err := db.Create(&holiday).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This day is already a holiday"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create holiday"})
+		return
+	}
+	c.JSON(http.StatusOK, holiday)
+}
+
+// AdminDeleteHoliday godoc
+// @Summary Удалить праздничный день (Админ)
+// @Description Удаляет нерабочий день из семестра.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Праздничного дня"
+// @Success 200 {object} map[string]interface{} "Праздничный день успешно удален"
+// @Failure 404 {object} map[string]interface{} "Праздничный день не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/holidays/{id} [delete]
+func AdminDeleteHoliday(c *gin.Context, db *gorm.DB) {
+	result := db.Delete(&models.Holiday{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete holiday"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Holiday deleted successfully"})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/throttle"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// codeAttemptBackoff locks students out after wrong attendance codes, counted
+// per student and session. The first three failures only count; every further
+// failure locks the student out for twice as long as the previous one.
+var codeAttemptBackoff = throttle.Backoff{FreeFailures: 3, BaseLockout: 5 * time.Second, MaxLockout: 15 * time.Minute}
+
+var errCodeAttemptsLocked = errors.New("too many failed code attempts")
+
+// codeLockedUntil returns when the student may try a code for the session
+// again, or nil if they are not locked out at now.
+func codeLockedUntil(db *gorm.DB, sessionID, studentID uint, now time.Time) (*time.Time, error) {
+	var attempt models.CodeAttempt
+	err := db.Where("session_id = ? AND student_id = ?", sessionID, studentID).First(&attempt).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if attempt.LockedUntil == nil || !attempt.LockedUntil.After(now) {
+		return nil, nil
+	}
+	return attempt.LockedUntil, nil
+}
+
+// recordFailedCode counts a wrong code and locks the student out once the
+// free attempts are used up. It must run outside the submit transaction so
+// the failure is kept when that transaction rolls back.
+func recordFailedCode(c *gin.Context, db *gorm.DB, lessonID, sessionID, studentID uint, now time.Time) (*time.Time, error) {
+	attempt := models.CodeAttempt{
+		SessionID:    sessionID,
+		StudentID:    studentID,
+		Failures:     1,
+		LastFailedAt: now,
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "session_id"}, {Name: "student_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":       gorm.Expr("code_attempts.failures + 1"),
+			"last_failed_at": now,
+		}),
+	}).Create(&attempt).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("session_id = ? AND student_id = ?", sessionID, studentID).First(&attempt).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("attendance: wrong code from student %d for lesson %d session %d (ip %s, %d failures)",
+		studentID, lessonID, sessionID, c.ClientIP(), attempt.Failures)
+
+	lockout := codeAttemptBackoff.Lockout(attempt.Failures)
+	if lockout == 0 {
+		return nil, nil
+	}
+	lockedUntil := now.Add(lockout)
+	if err := db.Model(&attempt).Update("locked_until", lockedUntil).Error; err != nil {
+		return nil, err
+	}
+	return &lockedUntil, nil
+}
+
+// respondLocked writes a 429 response telling the client when it may try
+// again.
+func respondLocked(c *gin.Context, errCode, message string, lockedUntil time.Time, now time.Time) {
+	retryAfter := int(math.Ceil(lockedUntil.Sub(now).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":        message,
+		"code":         errCode,
+		"retry_after":  retryAfter,
+		"locked_until": lockedUntil,
+	})
+}
+
+// respondCodeLocked writes the 429 response for a locked out student.
+func respondCodeLocked(c *gin.Context, lockedUntil time.Time, now time.Time) {
+	respondLocked(c, errCodeTooManyAttempts, "Too many wrong codes, try again later", lockedUntil, now)
+}
+
+// GetCodeAttempts godoc
+// @Summary Получить неудачные попытки ввода кода
+// @Description Возвращает студентов, вводивших неверный код в сессии занятия, с количеством ошибок и временем блокировки. Сессия выбирается по session_id или дате, по умолчанию - последняя.
+// @Tags teacher
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param session_id query int false "ID Сессии"
+// @Param date query string false "Дата сессии (YYYY-MM-DD)"
+// @Success 200 {array} models.CodeAttempt "Список попыток, больше всего ошибок - первыми"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/attempts [get]
+func GetCodeAttempts(c *gin.Context, db *gorm.DB) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonRead(c, db, lessonID) {
+		return
+	}
+
+	session, err := findSession(db, lessonID, c.Query("session_id"), c.Query("date"))
+	if err == errInvalidDate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusOK, []models.CodeAttempt{}) // No session held yet
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve code attempts"})
+		return
+	}
+
+	var attempts []models.CodeAttempt
+	if err := db.Preload("Student").
+		Where("session_id = ?", session.ID).
+		Order("failures desc, last_failed_at desc").
+		Find(&attempts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve code attempts"})
+		return
+	}
+	c.JSON(http.StatusOK, attempts)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"student-attendance-app/pkg/models"
+	"testing"
+	"time"
+)
+
+func TestSubmitAttendanceLocksOutAfterWrongCodes(t *testing.T) {
+	db := newTestDB(t)
+	f := newAttendanceFixture(t, db)
+
+	submit := func(code string) *http.Response {
+		t.Helper()
+		c, w := newTestContext(t, f.student.ID, SubmitAttendanceRequest{LessonID: f.lesson.ID, Code: code})
+		SubmitAttendance(c, db, testConfig)
+		return w.Result()
+	}
+
+	for i := 1; i <= codeAttemptBackoff.FreeFailures; i++ {
+		if resp := submit("00000"); resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("wrong code %d got status %d, want %d", i, resp.StatusCode, http.StatusBadRequest)
+		}
+	}
+	resp := submit("00000")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("wrong code %d got status %d, want %d", codeAttemptBackoff.FreeFailures+1, resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "5" {
+		t.Errorf("Retry-After = %q, want %q", got, "5")
+	}
+
+	// While locked out even the right code is refused and not counted
+	if resp := submit(f.code.Code); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("right code while locked out got status %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	var attempt models.CodeAttempt
+	if err := db.Where("session_id = ? AND student_id = ?", f.session.ID, f.student.ID).First(&attempt).Error; err != nil {
+		t.Fatalf("failed to load code attempts: %v", err)
+	}
+	if attempt.Failures != codeAttemptBackoff.FreeFailures+1 {
+		t.Errorf("failures = %d, want %d", attempt.Failures, codeAttemptBackoff.FreeFailures+1)
+	}
+
+	// Other students of the group are not affected
+	classmate := models.User{Identifier: "student003", Password: "x", Name: "Classmate", Email: "student003@example.com", Role: "student", GroupID: f.student.GroupID}
+	create(t, db, &classmate)
+	c, w := newTestContext(t, classmate.ID, SubmitAttendanceRequest{LessonID: f.lesson.ID, Code: f.code.Code})
+	SubmitAttendance(c, db, testConfig)
+	if w.Code != http.StatusOK {
+		t.Errorf("classmate got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	// Once the lockout is over the student can mark attendance
+	db.Model(&attempt).Update("locked_until", time.Now().Add(-time.Second))
+	if resp := submit(f.code.Code); resp.StatusCode != http.StatusOK {
+		t.Errorf("right code after the lockout got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"student-attendance-app/pkg/auth"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"`
+}
+
+// Refresh godoc
+// @Summary Обновить токен доступа
+// @Description Обменивает refresh_token на новую пару токенов. Каждый refresh_token действует один раз; повторное использование уже обмененного токена завершает сессию (code: refresh_token_reused).
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   request body RefreshRequest true "Refresh-токен"
+// @Success 200 {object} map[string]interface{} "Новые token, refresh_token и expires_in"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 401 {object} map[string]interface{} "Недействительный токен (code: invalid_refresh_token или refresh_token_reused)"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /auth/refresh [post]
+func Refresh(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := auth.Refresh(db, req.RefreshToken, cfg)
+	if err == auth.ErrRefreshTokenReused {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used, please log in again", "code": errCodeRefreshTokenReused})
+		return
+	}
+	if err == auth.ErrInvalidRefreshToken {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token", "code": errCodeInvalidRefreshToken})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Logout godoc
+// @Summary Выход
+// @Description Завершает сессию, к которой относится refresh_token. Токены доступа этой сессии перестают приниматься сразу.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   request body RefreshRequest true "Refresh-токен"
+// @Success 200 {object} map[string]interface{} "Сессия завершена"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /auth/logout [post]
+func Logout(c *gin.Context, db *gorm.DB) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Logging out with an unknown token is not an error: the session is gone
+	// either way
+	if err := auth.Logout(db, req.RefreshToken); err != nil && err != auth.ErrInvalidRefreshToken {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// AdminRevokeUserSessions godoc
+// @Summary Завершить все сессии пользователя (Админ)
+// @Description Завершает все сессии пользователя на всех устройствах. Выданные токены доступа перестают приниматься сразу, для продолжения работы нужно войти заново.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Пользователя"
+// @Success 200 {object} map[string]interface{} "Сессии завершены (revoked - количество)"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id}/sessions [delete]
+func AdminRevokeUserSessions(c *gin.Context, db *gorm.DB) {
+	userID, ok := paramUint(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	revoked, err := auth.RevokeUserSessions(db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked successfully", "revoked": revoked})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"student-attendance-app/pkg/checkin"
+	"student-attendance-app/pkg/codes"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ScanAttendanceRequest struct {
+	Payload string `json:"payload" binding:"required" example:"http://localhost:5173/checkin?t=eyJsIjoxfQ.c2ln"`
+}
+
+// checkinExpiry is when a token for code stops being accepted. Rotating
+// codes are only signed for the steps Verify still accepts.
+func checkinExpiry(cfg *config.Config, code models.GeneratedCode) time.Time {
+	if code.Mode != codes.ModeRotating || code.RotatesAt == nil {
+		return code.ExpiresAt
+	}
+	step := time.Duration(code.StepSeconds) * time.Second
+	expiry := code.RotatesAt.Add(time.Duration(cfg.CodeRotationSkew) * step)
+	if expiry.After(code.ExpiresAt) {
+		return code.ExpiresAt
+	}
+	return expiry
+}
+
+// GetCodeQR godoc
+// @Summary Получить QR-код для отметки
+// @Description Возвращает активный код занятия в виде QR-кода (PNG или SVG) с подписанной ссылкой для отметки. Для меняющегося кода изображение нужно запрашивать заново после rotates_at (заголовок X-Code-Rotates-At).
+// @Tags teacher
+// @Produce  png
+// @Produce  image/svg+xml
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param format query string false "Формат изображения: png (по умолчанию) или svg"
+// @Param size query int false "Размер PNG в пикселях, от 128 до 1024 (по умолчанию 512)"
+// @Success 200 {file} file "QR-код"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 404 {object} map[string]interface{} "Активный код не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/code/qr [get]
+func GetCodeQR(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	format := c.DefaultQuery("format", checkin.FormatPNG)
+	if format != checkin.FormatPNG && format != checkin.FormatSVG {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, expected png or svg"})
+		return
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(checkin.DefaultSize)))
+	if err != nil || size < checkin.MinSize || size > checkin.MaxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid size, expected 128 to 1024 pixels"})
+		return
+	}
+	if !requireLessonAccess(c, db, lessonID) {
+		return
+	}
+
+	var lesson models.Lesson
+	if err := db.First(&lesson, lessonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+
+	now := time.Now()
+	code, ok := activeCode(c, db, lesson.ID, now)
+	if !ok {
+		return
+	}
+	showCurrentCode(cfg, lesson, &code, now)
+
+	claims := checkin.Claims{
+		LessonID:  lesson.ID,
+		Code:      code.Code,
+		ExpiresAt: checkinExpiry(cfg, code).Unix(),
+	}
+	if code.SessionID != nil {
+		claims.SessionID = *code.SessionID
+	}
+	token, err := checkin.Sign(cfg.JWTSecret, claims)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+	link := checkin.URL(cfg.PublicURL, token)
+
+	var image []byte
+	contentType := "image/png"
+	if format == checkin.FormatSVG {
+		image, err = checkin.SVG(link)
+		contentType = "image/svg+xml"
+	} else {
+		image, err = checkin.PNG(link, size)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if code.RotatesAt != nil {
+		c.Header("X-Code-Rotates-At", code.RotatesAt.UTC().Format(time.RFC3339))
+	}
+	c.Data(http.StatusOK, contentType, image)
+}
+
+// ScanAttendance godoc
+// @Summary Отметить посещаемость по QR-коду
+// @Description Студент отправляет содержимое отсканированного QR-кода (ссылку или токен) вместо ID занятия и кода. Токен подписан сервером и действует, пока действует показанный код.
+// @Tags student
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   scan body ScanAttendanceRequest true "Содержимое QR-кода"
+// @Success 200 {object} map[string]interface{} "Посещаемость успешно отмечена или уже была отмечена ранее (already_marked)"
+// @Failure 400 {object} map[string]interface{} "Неверная или просроченная ссылка (code: invalid_checkin) или неверный код"
+// @Failure 403 {object} map[string]interface{} "Студент не состоит в группе занятия (code: not_in_lesson_group)"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 429 {object} map[string]interface{} "Слишком много неверных кодов (code: too_many_attempts)"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/attendance/scan [post]
+func ScanAttendance(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+	var req ScanAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := checkin.Parse(cfg.JWTSecret, checkin.TokenFromPayload(req.Payload), time.Now())
+	if err == checkin.ErrExpiredToken {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Check-in link has expired, scan the code again", "code": errCodeInvalidCheckin})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid check-in link", "code": errCodeInvalidCheckin})
+		return
+	}
+
+	var sessionID *uint
+	if claims.SessionID != 0 {
+		sessionID = &claims.SessionID
+	}
+	markAttendance(c, db, cfg, claims.LessonID, claims.Code, sessionID)
+}
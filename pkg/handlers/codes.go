@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"student-attendance-app/pkg/codes"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ensureCodeSecret gives the session a secret for rotating codes if it does
+// not have one yet. Concurrent callers end up with the same secret.
+func ensureCodeSecret(db *gorm.DB, session *models.LessonSession) error {
+	if len(session.CodeSecret) > 0 {
+		return nil
+	}
+	secret, err := codes.NewSecret()
+	if err != nil {
+		return err
+	}
+	if err := db.Model(&models.LessonSession{}).
+		Where("id = ? AND code_secret IS NULL", session.ID).
+		Update("code_secret", secret).Error; err != nil {
+		return err
+	}
+	return db.First(session, session.ID).Error
+}
+
+// codeMatches reports whether value matches the active code at now. code must
+// have its Session loaded.
+func codeMatches(cfg *config.Config, lesson models.Lesson, code models.GeneratedCode, value string, now time.Time) bool {
+	if code.Mode != codes.ModeRotating {
+		return subtle.ConstantTimeCompare([]byte(code.Code), []byte(value)) == 1
+	}
+	if len(code.Session.CodeSecret) == 0 || code.StepSeconds <= 0 {
+		return false
+	}
+	length, alphabet, _ := codeSettings(cfg, lesson)
+	step := time.Duration(code.StepSeconds) * time.Second
+	return codes.Verify(code.Session.CodeSecret, value, now, step, cfg.CodeRotationSkew, length, alphabet)
+}
+
+// showCurrentCode fills in the value a rotating code has at now and when it
+// changes next. code must have its Session loaded.
+func showCurrentCode(cfg *config.Config, lesson models.Lesson, code *models.GeneratedCode, now time.Time) {
+	if code.Mode != codes.ModeRotating || code.StepSeconds <= 0 {
+		return
+	}
+	length, alphabet, _ := codeSettings(cfg, lesson)
+	step := time.Duration(code.StepSeconds) * time.Second
+	code.Code = codes.Derive(code.Session.CodeSecret, codes.Counter(now, step), length, alphabet)
+	rotatesAt := codes.StepEnd(now, step)
+	code.RotatesAt = &rotatesAt
+}
+
+// GetCurrentCode godoc
+// @Summary Получить текущий код посещаемости
+// @Description Возвращает активный код занятия для показа студентам. Для меняющегося кода возвращает значение текущего временного окна и время следующей смены (rotates_at).
+// @Tags teacher
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Success 200 {object} models.GeneratedCode "Активный код"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 404 {object} map[string]interface{} "Активный код не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/code [get]
+func GetCurrentCode(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonAccess(c, db, lessonID) {
+		return
+	}
+
+	var lesson models.Lesson
+	if err := db.First(&lesson, lessonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+
+	now := time.Now()
+	code, ok := activeCode(c, db, lesson.ID, now)
+	if !ok {
+		return
+	}
+
+	showCurrentCode(cfg, lesson, &code, now)
+	c.JSON(http.StatusOK, code)
+}
+
+// activeCode loads the lesson's active code with its session, writing a 404
+// or 500 response when there is none.
+func activeCode(c *gin.Context, db *gorm.DB, lessonID uint, now time.Time) (models.GeneratedCode, bool) {
+	var code models.GeneratedCode
+	err := db.Preload("Session").
+		Where("lesson_id = ? AND is_active = ? AND expires_at > ?", lessonID, true, now).
+		Order("created_at desc").
+		First(&code).Error
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No active code found for this lesson"})
+		return code, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve code"})
+		return code, false
+	}
+	return code, true
+}
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CuratorRequest struct {
+	CuratorID *uint `json:"curator_id" example:"4"` // Removes the curator when null
+}
+
+// JournalResponse is a group's attendance journal: every held session of the
+// group's lessons against every student of the group.
+type JournalResponse struct {
+	Group    models.Group           `json:"group"`
+	Students []models.User          `json:"students"`
+	Sessions []models.LessonSession `json:"sessions"`
+	Marks    []JournalMark          `json:"marks"`
+}
+
+// JournalMark is one filled cell of the journal. Empty cells are absences;
+// absences covered by an approved excuse are filled as excused.
+type JournalMark struct {
+	SessionID   uint       `json:"session_id"`
+	StudentID   uint       `json:"student_id"`
+	SubmittedAt *time.Time `json:"submitted_at"` // Nil for excused absences
+	Status      string     `json:"status"`
+	MinutesLate int        `json:"minutes_late"`
+	Source      string     `json:"source"` // 'code', 'manual' or 'excuse'
+}
+
+// AttendanceStats summarises a student's attendance of the held sessions of
+// their group's lessons.
+type AttendanceStats struct {
+	Student  models.User `json:"student"`
+	Sessions int64       `json:"sessions"` // Sessions the student was expected at
+	Attended int64       `json:"attended"` // Present or late
+	Late     int64       `json:"late"`
+	Excused  int64       `json:"excused"`
+	Missed   int64       `json:"missed"`              // Neither attended nor excused
+	Rate     float64     `json:"rate" example:"87.5"` // Percentage of sessions attended
+}
+
+// curatedGroupIDs selects the IDs of the groups whose roster and attendance
+// the current user may read: the groups they curate, or every group for
+// holders of attendance.read.all.
+func curatedGroupIDs(c *gin.Context, db *gorm.DB) *gorm.DB {
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&models.Group{}).Select("id")
+	if rbac.Has(c, rbac.AttendanceReadAll) {
+		return query
+	}
+	return query.Where("curator_id = ?", currentUserID(c))
+}
+
+// findCuratedGroup loads the group from the id path parameter if the current
+// user may read it. It writes a 400, 404 or 500 response and returns false
+// otherwise; groups out of scope are reported as not found.
+func findCuratedGroup(c *gin.Context, db *gorm.DB) (models.Group, bool) {
+	var group models.Group
+	groupID, ok := paramUint(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+		return group, false
+	}
+	err := db.Where("id IN (?)", curatedGroupIDs(c, db)).First(&group, groupID).Error
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return group, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve group"})
+		return group, false
+	}
+	return group, true
+}
+
+// curatedStudents scopes a user query to the students of the group, provided
+// the current user may read it. Staff accounts that belong to the group are
+// left out.
+func curatedStudents(c *gin.Context, db *gorm.DB, groupID uint) *gorm.DB {
+	return db.Where("group_id = ? AND group_id IN (?) AND role = ?", groupID, curatedGroupIDs(c, db), "student")
+}
+
+// heldSessions selects the IDs of sessions that have started, within the term
+// given by the term_id query parameter if any. It writes a 400 response and
+// returns false when the term does not exist.
+func heldSessions(c *gin.Context, db *gorm.DB) (*gorm.DB, bool) {
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&models.LessonSession{}).
+		Select("id").
+		Where("starts_at <= ?", time.Now())
+	if termID := c.Query("term_id"); termID != "" {
+		var term models.Term
+		if err := db.First(&term, termID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Term not found"})
+			return nil, false
+		}
+		query = query.Where("date BETWEEN ? AND ?", term.StartDate, term.EndDate)
+	}
+	return query, true
+}
+
+// attendanceStats counts, for each student, the sessions among sessionIDs
+// held for their current group and the ones they attended. Unmarked sessions
+// covered by an approved excuse count as excused.
+func attendanceStats(db *gorm.DB, students []models.User, sessionIDs *gorm.DB) ([]AttendanceStats, error) {
+	stats := make([]AttendanceStats, 0, len(students))
+	if len(students) == 0 {
+		return stats, nil
+	}
+	ids := make([]uint, 0, len(students))
+	for _, student := range students {
+		ids = append(ids, student.ID)
+	}
+
+	var counts []struct {
+		StudentID uint
+		Sessions  int64
+		Attended  int64
+		Late      int64
+		Excused   int64
+	}
+	err := db.Table("users").
+		Select(`users.id AS student_id, COUNT(lesson_sessions.id) AS sessions,
+			COUNT(attendances.id) FILTER (WHERE attendances.status IN (?, ?)) AS attended,
+			COUNT(attendances.id) FILTER (WHERE attendances.status = ?) AS late,
+			COUNT(lesson_sessions.id) FILTER (WHERE attendances.status = ?
+				OR (attendances.id IS NULL AND `+excuseCovers("users.id", "lesson_sessions.date")+`)) AS excused`,
+			models.AttendancePresent, models.AttendanceLate, models.AttendanceLate, models.AttendanceExcused).
+		Joins("JOIN lessons ON lessons.group_id = users.group_id").
+		Joins("JOIN lesson_sessions ON lesson_sessions.lesson_id = lessons.id").
+		Joins("LEFT JOIN attendances ON attendances.session_id = lesson_sessions.id AND attendances.student_id = users.id").
+		Where("users.id IN ? AND lesson_sessions.id IN (?)", ids, sessionIDs).
+		Group("users.id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byStudent := make(map[uint]int, len(counts))
+	for i, count := range counts {
+		byStudent[count.StudentID] = i
+	}
+	for _, student := range students {
+		entry := AttendanceStats{Student: student}
+		if i, ok := byStudent[student.ID]; ok {
+			entry.Sessions = counts[i].Sessions
+			entry.Attended = counts[i].Attended
+			entry.Late = counts[i].Late
+			entry.Excused = counts[i].Excused
+			entry.Missed = entry.Sessions - entry.Attended - entry.Excused
+		}
+		if entry.Sessions > 0 {
+			entry.Rate = math.Round(float64(entry.Attended)/float64(entry.Sessions)*1000) / 10
+		}
+		stats = append(stats, entry)
+	}
+	return stats, nil
+}
+
+// GetCuratedGroups godoc
+// @Summary Получить курируемые группы
+// @Description Возвращает группы, куратором которых назначен залогиненный пользователь. Пользователям с правом attendance.read.all возвращаются все группы.
+// @Tags curator
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} models.Group "Список групп"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/groups [get]
+func GetCuratedGroups(c *gin.Context, db *gorm.DB) {
+	var groups []models.Group
+	if err := db.Preload("Curator").Where("id IN (?)", curatedGroupIDs(c, db)).Order("name").Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve groups"})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetCuratedGroupStudents godoc
+// @Summary Получить состав курируемой группы
+// @Description Возвращает студентов группы. Доступно только куратору группы и пользователям с правом attendance.read.all.
+// @Tags curator
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Success 200 {array} models.User "Список студентов"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена или не курируется пользователем"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/groups/{id}/students [get]
+func GetCuratedGroupStudents(c *gin.Context, db *gorm.DB) {
+	group, ok := findCuratedGroup(c, db)
+	if !ok {
+		return
+	}
+
+	var students []models.User
+	if err := curatedStudents(c, db, group.ID).Order("name").Find(&students).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve students"})
+		return
+	}
+	c.JSON(http.StatusOK, students)
+}
+
+// GetCuratedGroupJournal godoc
+// @Summary Получить журнал посещаемости курируемой группы
+// @Description Возвращает студентов группы, проведенные занятия (сессии) ее расписания и отметки о присутствии, опционально только за указанный семестр. Доступно только куратору группы и пользователям с правом attendance.read.all.
+// @Tags curator
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Param term_id query int false "ID Семестра"
+// @Success 200 {object} JournalResponse "Журнал посещаемости"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или семестр не найден"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена или не курируется пользователем"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/groups/{id}/journal [get]
+func GetCuratedGroupJournal(c *gin.Context, db *gorm.DB) {
+	group, ok := findCuratedGroup(c, db)
+	if !ok {
+		return
+	}
+	sessionIDs, ok := heldSessions(c, db)
+	if !ok {
+		return
+	}
+
+	journal := JournalResponse{Group: group, Marks: []JournalMark{}}
+	if err := curatedStudents(c, db, group.ID).Order("name").Find(&journal.Students).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve students"})
+		return
+	}
+
+	err := db.Preload("Lesson").
+		Joins("JOIN lessons ON lessons.id = lesson_sessions.lesson_id").
+		Where("lessons.group_id = ? AND lessons.group_id IN (?)", group.ID, curatedGroupIDs(c, db)).
+		Where("lesson_sessions.id IN (?)", sessionIDs).
+		Order("lesson_sessions.starts_at").
+		Find(&journal.Sessions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	if len(journal.Students) > 0 && len(journal.Sessions) > 0 {
+		studentIDs := make([]uint, 0, len(journal.Students))
+		for _, student := range journal.Students {
+			studentIDs = append(studentIDs, student.ID)
+		}
+		journalSessionIDs := make([]uint, 0, len(journal.Sessions))
+		for _, session := range journal.Sessions {
+			journalSessionIDs = append(journalSessionIDs, session.ID)
+		}
+		if err := db.Model(&models.Attendance{}).
+			Select("session_id, student_id, submitted_at, status, minutes_late, source").
+			Where("session_id IN ? AND student_id IN ?", journalSessionIDs, studentIDs).
+			Scan(&journal.Marks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance records"})
+			return
+		}
+
+		excuses, err := approvedExcuses(db, studentIDs, journal.Sessions[0].Date, journal.Sessions[len(journal.Sessions)-1].Date)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve excuses"})
+			return
+		}
+		marked := make(map[[2]uint]bool, len(journal.Marks))
+		for _, mark := range journal.Marks {
+			marked[[2]uint{mark.SessionID, mark.StudentID}] = true
+		}
+		for _, session := range journal.Sessions {
+			for _, studentID := range studentIDs {
+				if !marked[[2]uint{session.ID, studentID}] && isExcused(excuses[studentID], session.Date) {
+					journal.Marks = append(journal.Marks, JournalMark{
+						SessionID: session.ID,
+						StudentID: studentID,
+						Status:    models.AttendanceExcused,
+						Source:    models.AttendanceSourceExcuse,
+					})
+				}
+			}
+		}
+	}
+	c.JSON(http.StatusOK, journal)
+}
+
+// GetCuratedGroupStats godoc
+// @Summary Получить статистику пропусков курируемой группы
+// @Description Возвращает для каждого студента группы число проведенных занятий, посещенных и пропущенных, и процент посещаемости, опционально только за указанный семестр. Доступно только куратору группы и пользователям с правом attendance.read.all.
+// @Tags curator
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Param term_id query int false "ID Семестра"
+// @Success 200 {array} AttendanceStats "Статистика по студентам"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или семестр не найден"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена или не курируется пользователем"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/groups/{id}/stats [get]
+func GetCuratedGroupStats(c *gin.Context, db *gorm.DB) {
+	group, ok := findCuratedGroup(c, db)
+	if !ok {
+		return
+	}
+	sessionIDs, ok := heldSessions(c, db)
+	if !ok {
+		return
+	}
+
+	var students []models.User
+	if err := curatedStudents(c, db, group.ID).Order("name").Find(&students).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve students"})
+		return
+	}
+	stats, err := attendanceStats(db, students, sessionIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute attendance statistics"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// AdminSetGroupCurator godoc
+// @Summary Назначить куратора группы (Админ)
+// @Description Назначает пользователя куратором группы и выдает ему роль curator, либо снимает куратора, если curator_id равен null. Куратор видит состав и посещаемость только своих групп.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Param curator body CuratorRequest true "ID Куратора"
+// @Success 200 {object} models.Group "Обновленная группа"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или пользователь не найден"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/groups/{id}/curator [put]
+func AdminSetGroupCurator(c *gin.Context, db *gorm.DB) {
+	var group models.Group
+	if err := db.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	var req CuratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var curator models.User
+	if req.CuratorID != nil {
+		if err := db.First(&curator, *req.CuratorID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Curator not found"})
+			return
+		}
+		if curator.Status != models.UserActive {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Curator account is not active"})
+			return
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&group).Update("curator_id", req.CuratorID).Error; err != nil {
+			return err
+		}
+		if req.CuratorID == nil {
+			return nil
+		}
+		return rbac.AssignRole(tx, curator.ID, rbac.RoleCurator)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set curator"})
+		return
+	}
+
+	group.CuratorID = req.CuratorID
+	if req.CuratorID != nil {
+		group.Curator = &curator
+	}
+	c.JSON(http.StatusOK, group)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// curatorFixture is a curated group with two students and a teacher account
+// that also belongs to the group, and another group with one student.
+type curatorFixture struct {
+	curator      models.User
+	group        models.Group
+	other        models.Group
+	students     []models.User
+	otherStudent models.User
+}
+
+func newCuratorFixture(t *testing.T, db *gorm.DB) curatorFixture {
+	t.Helper()
+	var f curatorFixture
+	f.curator = models.User{Identifier: "curator001", Password: "x", Name: "Curator", Email: "curator001@example.com", Role: "teacher"}
+	create(t, db, &f.curator)
+	f.group = models.Group{Name: "Group A", CuratorID: &f.curator.ID}
+	f.other = models.Group{Name: "Group B"}
+	create(t, db, &f.group, &f.other)
+
+	f.students = []models.User{
+		{Identifier: "student001", Password: "x", Name: "Anna", Email: "student001@example.com", Role: "student", GroupID: &f.group.ID},
+		{Identifier: "student002", Password: "x", Name: "Boris", Email: "student002@example.com", Role: "student", GroupID: &f.group.ID},
+	}
+	f.otherStudent = models.User{Identifier: "student003", Password: "x", Name: "Vera", Email: "student003@example.com", Role: "student", GroupID: &f.other.ID}
+	teacher := models.User{Identifier: "teacher001", Password: "x", Name: "Teacher", Email: "teacher001@example.com", Role: "teacher", GroupID: &f.group.ID}
+	create(t, db, &f.students[0], &f.students[1], &f.otherStudent, &teacher)
+	return f
+}
+
+// curatorRequest runs handler as userID with the permissions and the id path
+// parameter, and returns the response.
+func curatorRequest(t *testing.T, userID uint, permissions map[string]bool, id uint, body interface{}, handler func(c *gin.Context)) (int, []byte) {
+	t.Helper()
+	c, w := newTestContext(t, userID, body)
+	c.Params = gin.Params{{Key: "id", Value: strconv.Itoa(int(id))}}
+	rbac.SetPermissions(c, permissions)
+	handler(c)
+	return w.Code, w.Body.Bytes()
+}
+
+var curatorPermissions = map[string]bool{rbac.AttendanceReadGroup: true, rbac.GroupsInvite: true}
+
+func TestGetCuratedGroupStudentsScope(t *testing.T) {
+	db := newTestDB(t)
+	f := newCuratorFixture(t, db)
+	handler := func(c *gin.Context) { GetCuratedGroupStudents(c, db) }
+
+	status, body := curatorRequest(t, f.curator.ID, curatorPermissions, f.group.ID, nil, handler)
+	if status != http.StatusOK {
+		t.Fatalf("own group got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	var students []models.User
+	if err := json.Unmarshal(body, &students); err != nil {
+		t.Fatalf("failed to decode students: %v", err)
+	}
+	// Only students, and only of the curated group
+	if len(students) != 2 || students[0].ID != f.students[0].ID || students[1].ID != f.students[1].ID {
+		t.Errorf("students = %+v, want %s and %s", students, f.students[0].Name, f.students[1].Name)
+	}
+
+	if status, body := curatorRequest(t, f.curator.ID, curatorPermissions, f.other.ID, nil, handler); status != http.StatusNotFound {
+		t.Errorf("other group got status %d, want %d: %s", status, http.StatusNotFound, body)
+	}
+
+	// attendance.read.all opens every group
+	status, body = curatorRequest(t, f.curator.ID, map[string]bool{rbac.AttendanceReadAll: true}, f.other.ID, nil, handler)
+	if status != http.StatusOK {
+		t.Fatalf("other group with attendance.read.all got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	if err := json.Unmarshal(body, &students); err != nil || len(students) != 1 || students[0].ID != f.otherStudent.ID {
+		t.Errorf("students = %s, want only %s", body, f.otherStudent.Name)
+	}
+}
+
+func TestGetCuratedGroupStats(t *testing.T) {
+	db := newTestDB(t)
+	f := newCuratorFixture(t, db)
+
+	lesson := models.Lesson{Name: "Math", Day: "Понедельник", Time: "09:00-10:30", GroupID: &f.group.ID}
+	otherLesson := models.Lesson{Name: "Physics", Day: "Понедельник", Time: "09:00-10:30", GroupID: &f.other.ID}
+	create(t, db, &lesson, &otherLesson)
+	held := time.Now().Add(-24 * time.Hour)
+	sessions := []models.LessonSession{
+		{LessonID: lesson.ID, Date: held.AddDate(0, 0, -7).Truncate(24 * time.Hour), StartsAt: held.AddDate(0, 0, -7), EndsAt: held.AddDate(0, 0, -7).Add(time.Hour)},
+		{LessonID: lesson.ID, Date: held.Truncate(24 * time.Hour), StartsAt: held, EndsAt: held.Add(time.Hour)},
+		{LessonID: otherLesson.ID, Date: held.Truncate(24 * time.Hour), StartsAt: held, EndsAt: held.Add(time.Hour)},
+	}
+	create(t, db, &sessions[0], &sessions[1], &sessions[2])
+	create(t, db,
+		&models.Attendance{LessonID: lesson.ID, SessionID: &sessions[0].ID, StudentID: f.students[0].ID, SubmittedAt: held},
+		&models.Attendance{LessonID: lesson.ID, SessionID: &sessions[1].ID, StudentID: f.students[0].ID, SubmittedAt: held},
+		&models.Attendance{LessonID: lesson.ID, SessionID: &sessions[1].ID, StudentID: f.students[1].ID, SubmittedAt: held},
+	)
+
+	status, body := curatorRequest(t, f.curator.ID, curatorPermissions, f.group.ID, nil, func(c *gin.Context) { GetCuratedGroupStats(c, db) })
+	if status != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	var stats []AttendanceStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	want := []AttendanceStats{
+		{Student: f.students[0], Sessions: 2, Attended: 2, Missed: 0, Rate: 100},
+		{Student: f.students[1], Sessions: 2, Attended: 1, Missed: 1, Rate: 50},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("stats = %+v, want %d entries", stats, len(want))
+	}
+	for i := range want {
+		got := stats[i]
+		if got.Student.ID != want[i].Student.ID || got.Sessions != want[i].Sessions || got.Attended != want[i].Attended ||
+			got.Missed != want[i].Missed || got.Rate != want[i].Rate {
+			t.Errorf("stats[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestCuratedInvitesScope(t *testing.T) {
+	db := newTestDB(t)
+	f := newCuratorFixture(t, db)
+	createHandler := func(c *gin.Context) { CreateCuratedInvite(c, db) }
+	revokeHandler := func(c *gin.Context) { RevokeCuratedInvite(c, db) }
+
+	status, body := curatorRequest(t, f.curator.ID, curatorPermissions, f.group.ID, InviteRequest{}, createHandler)
+	if status != http.StatusOK {
+		t.Fatalf("invite to own group got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	var own models.GroupInvite
+	if err := json.Unmarshal(body, &own); err != nil || own.GroupID != f.group.ID {
+		t.Fatalf("invite = %s, want one to group %d", body, f.group.ID)
+	}
+	if status, body := curatorRequest(t, f.curator.ID, curatorPermissions, f.other.ID, InviteRequest{}, createHandler); status != http.StatusNotFound {
+		t.Errorf("invite to other group got status %d, want %d: %s", status, http.StatusNotFound, body)
+	}
+
+	foreign := models.GroupInvite{GroupID: f.other.ID, Code: "FOREIGN1", CreatedByID: f.curator.ID}
+	create(t, db, &foreign)
+	if status, body := curatorRequest(t, f.curator.ID, curatorPermissions, foreign.ID, nil, revokeHandler); status != http.StatusNotFound {
+		t.Errorf("revoking other group's invite got status %d, want %d: %s", status, http.StatusNotFound, body)
+	}
+	if db.First(&foreign, foreign.ID); foreign.RevokedAt != nil {
+		t.Errorf("other group's invite was revoked")
+	}
+
+	if status, body := curatorRequest(t, f.curator.ID, curatorPermissions, own.ID, nil, revokeHandler); status != http.StatusOK {
+		t.Errorf("revoking own invite got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	if db.First(&own, own.ID); own.RevokedAt == nil {
+		t.Errorf("own invite was not revoked")
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/auth"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/mailer"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Email verification links are valid for a day.
+const emailVerificationTTL = 24 * time.Hour
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"`
+}
+
+type ResendVerificationRequest struct {
+	Login string `json:"login" binding:"required" example:"newstudent"` // Identifier or email
+}
+
+// mailEmailVerification mails the user a link confirming their address.
+func mailEmailVerification(db *gorm.DB, cfg *config.Config, m mailer.Mailer, user models.User) error {
+	return mailUserToken(db, cfg, m, user, models.TokenEmailVerification, "/verify-email", emailVerificationTTL, func(link string) mailer.Message {
+		return mailer.Message{
+			To:      user.Email,
+			Subject: "Подтверждение email",
+			Body: fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы подтвердить адрес и войти в систему учета посещаемости, откройте ссылку:\n%s\n\nСсылка действует 24 часа.\n",
+				user.Name, link),
+		}
+	})
+}
+
+// VerifyEmail godoc
+// @Summary Подтвердить email
+// @Description Подтверждает email пользователя по одноразовому токену из письма, отправленного при регистрации. После этого пользователь может войти.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   request body VerifyEmailRequest true "Токен из письма"
+// @Success 200 {object} map[string]interface{} "Email подтвержден"
+// @Failure 400 {object} map[string]interface{} "Неверный или просроченный токен (code: invalid_token)"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /auth/verify-email [post]
+func VerifyEmail(c *gin.Context, db *gorm.DB) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		token, err := auth.ConsumeUserToken(tx, req.Token, models.TokenEmailVerification)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+	if err == auth.ErrInvalidUserToken {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token", "code": errCodeInvalidToken})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully, you can log in now"})
+}
+
+// ResendVerification godoc
+// @Summary Повторно отправить письмо для подтверждения email
+// @Description Отправляет новую ссылку для подтверждения email, если адрес пользователя еще не подтвержден. Ответ одинаков для любых логинов.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   request body ResendVerificationRequest true "Логин или email"
+// @Success 200 {object} map[string]interface{} "Запрос принят"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Router /auth/resend-verification [post]
+func ResendVerification(c *gin.Context, db *gorm.DB, cfg *config.Config, m mailer.Mailer) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	defer c.JSON(http.StatusOK, gin.H{"message": "If the account exists and is not verified yet, a new link has been sent to its email"})
+
+	login := strings.TrimSpace(req.Login)
+	var user models.User
+	if err := db.Where("(identifier = ? OR LOWER(email) = LOWER(?)) AND email_verified_at IS NULL", login, login).
+		First(&user).Error; err != nil {
+		return
+	}
+	if err := mailEmailVerification(db, cfg, m, user); err != nil {
+		log.Printf("auth: failed to issue email verification token for user %d: %v", user.ID, err)
+	}
+}
+
+// AdminVerifyUserEmail godoc
+// @Summary Подтвердить email пользователя (Админ)
+// @Description Отмечает email пользователя как подтвержденный без письма, например если письмо не доходит.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Пользователя"
+// @Success 200 {object} models.User "Пользователь с подтвержденным email"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id}/verify-email [post]
+func AdminVerifyUserEmail(c *gin.Context, db *gorm.DB) {
+	userID, ok := paramUint(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := db.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+		user.EmailVerifiedAt = &now
+	}
+	c.JSON(http.StatusOK, user)
+}
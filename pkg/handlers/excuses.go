@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"student-attendance-app/pkg/auth"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/mailer"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/storage"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ExcuseReviewRequest struct {
+	Comment string `json:"comment" example:"Справка принята"`
+}
+
+// excuseFileTypes maps the accepted document types to the extension they are
+// stored with. Types are sniffed from the content, not taken from the client.
+var excuseFileTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+}
+
+// excuseCovers is an SQL condition that holds when the student in
+// studentColumn has an approved excuse covering the date in dateColumn.
+func excuseCovers(studentColumn, dateColumn string) string {
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM excuse_requests
+		WHERE excuse_requests.student_id = %s AND excuse_requests.status = '%s'
+		AND %s BETWEEN excuse_requests.start_date AND excuse_requests.end_date)`,
+		studentColumn, models.ExcuseApproved, dateColumn)
+}
+
+// approvedExcuses loads the approved excuses of the students that overlap the
+// dates from to to, by student.
+func approvedExcuses(db *gorm.DB, studentIDs []uint, from, to time.Time) (map[uint][]models.ExcuseRequest, error) {
+	byStudent := make(map[uint][]models.ExcuseRequest)
+	if len(studentIDs) == 0 {
+		return byStudent, nil
+	}
+	var excuses []models.ExcuseRequest
+	err := db.Where("student_id IN ? AND status = ? AND start_date <= ? AND end_date >= ?",
+		studentIDs, models.ExcuseApproved, to, from).
+		Find(&excuses).Error
+	for _, excuse := range excuses {
+		byStudent[excuse.StudentID] = append(byStudent[excuse.StudentID], excuse)
+	}
+	return byStudent, err
+}
+
+// isExcused reports whether any of the excuses covers the session date.
+func isExcused(excuses []models.ExcuseRequest, date time.Time) bool {
+	for _, excuse := range excuses {
+		if !date.Before(excuse.StartDate) && !date.After(excuse.EndDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// curatedExcuses scopes an excuse query to the students of the groups the
+// current user may read.
+func curatedExcuses(c *gin.Context, db *gorm.DB) *gorm.DB {
+	students := db.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).
+		Select("id").
+		Where("group_id IN (?) AND role = ?", curatedGroupIDs(c, db), "student")
+	return db.Where("student_id IN (?)", students)
+}
+
+// serveExcuseFile sends the document attached to the excuse.
+func serveExcuseFile(c *gin.Context, store storage.Storage, excuse models.ExcuseRequest) {
+	file, err := store.Open(excuse.FileKey)
+	if err == storage.ErrNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer file.Close()
+	disposition := mime.FormatMediaType("inline", map[string]string{"filename": excuse.FileName})
+	c.DataFromReader(http.StatusOK, excuse.FileSize, excuse.ContentType, file, map[string]string{
+		"Content-Disposition": disposition,
+	})
+}
+
+// GetMyExcuses godoc
+// @Summary Получить мои объяснительные
+// @Description Возвращает объяснительные (справки) залогиненного студента со статусом рассмотрения, начиная с последних.
+// @Tags student
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} models.ExcuseRequest "Список объяснительных"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/excuses [get]
+func GetMyExcuses(c *gin.Context, db *gorm.DB) {
+	var excuses []models.ExcuseRequest
+	if err := db.Preload("ReviewedBy").Where("student_id = ?", currentUserID(c)).Order("created_at desc").Find(&excuses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve excuses"})
+		return
+	}
+	c.JSON(http.StatusOK, excuses)
+}
+
+// SubmitExcuse godoc
+// @Summary Подать объяснительную
+// @Description Студент подает объяснительную за период с приложенным документом (PDF или JPEG), например медицинской справкой. После одобрения куратором или администратором пропуски в этом периоде считаются пропусками по уважительной причине.
+// @Tags student
+// @Accept  multipart/form-data
+// @Produce  json
+// @Security BearerAuth
+// @Param start_date formData string true "Первый день (YYYY-MM-DD)"
+// @Param end_date formData string true "Последний день (YYYY-MM-DD)"
+// @Param reason formData string false "Причина"
+// @Param file formData file true "Документ (PDF или JPEG)"
+// @Success 200 {object} models.ExcuseRequest "Поданная объяснительная"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или тип файла"
+// @Failure 413 {object} map[string]interface{} "Файл слишком большой"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/excuses [post]
+func SubmitExcuse(c *gin.Context, db *gorm.DB, cfg *config.Config, store storage.Storage) {
+	// Leave some room for the other form fields
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxUploadSize+1<<20)
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form"})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", c.PostForm("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, expected YYYY-MM-DD"})
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", c.PostForm("end_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, expected YYYY-MM-DD"})
+		return
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required"})
+		return
+	}
+	if header.Size > cfg.MaxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := excuseFileTypes[contentType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only PDF and JPEG files are accepted"})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	studentID := currentUserID(c)
+	name, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		return
+	}
+	key := fmt.Sprintf("excuses/%d/%s%s", studentID, name, ext)
+	if err := store.Put(key, file); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+		return
+	}
+
+	excuse := models.ExcuseRequest{
+		StudentID:   studentID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Reason:      strings.TrimSpace(c.PostForm("reason")),
+		FileKey:     key,
+		FileName:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		FileSize:    header.Size,
+		Status:      models.ExcusePending,
+	}
+	if err := db.Create(&excuse).Error; err != nil {
+		if err := store.Delete(key); err != nil {
+			log.Printf("storage: failed to delete %s: %v", key, err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit excuse"})
+		return
+	}
+	c.JSON(http.StatusOK, excuse)
+}
+
+// GetMyExcuseFile godoc
+// @Summary Скачать документ моей объяснительной
+// @Description Возвращает файл, приложенный к объяснительной залогиненного студента.
+// @Tags student
+// @Produce  application/pdf
+// @Produce  image/jpeg
+// @Security BearerAuth
+// @Param id path int true "ID Объяснительной"
+// @Success 200 {file} file "Документ"
+// @Failure 404 {object} map[string]interface{} "Объяснительная или файл не найдены"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/excuses/{id}/file [get]
+func GetMyExcuseFile(c *gin.Context, db *gorm.DB, store storage.Storage) {
+	var excuse models.ExcuseRequest
+	if err := db.Where("student_id = ?", currentUserID(c)).First(&excuse, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Excuse not found"})
+		return
+	}
+	serveExcuseFile(c, store, excuse)
+}
+
+// WithdrawExcuse godoc
+// @Summary Отозвать объяснительную
+// @Description Студент отзывает объяснительную, которая еще не рассмотрена. Приложенный файл удаляется.
+// @Tags student
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Объяснительной"
+// @Success 200 {object} map[string]interface{} "Объяснительная отозвана"
+// @Failure 404 {object} map[string]interface{} "Объяснительная не найдена"
+// @Failure 409 {object} map[string]interface{} "Объяснительная уже рассмотрена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/excuses/{id} [delete]
+func WithdrawExcuse(c *gin.Context, db *gorm.DB, store storage.Storage) {
+	var excuse models.ExcuseRequest
+	if err := db.Where("student_id = ?", currentUserID(c)).First(&excuse, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Excuse not found"})
+		return
+	}
+
+	result := db.Where("status = ?", models.ExcusePending).Delete(&models.ExcuseRequest{}, excuse.ID)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw excuse"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Excuse has already been reviewed"})
+		return
+	}
+	if err := store.Delete(excuse.FileKey); err != nil {
+		log.Printf("storage: failed to delete %s: %v", excuse.FileKey, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Excuse withdrawn successfully"})
+}
+
+// GetCuratedExcuses godoc
+// @Summary Получить объяснительные студентов
+// @Description Возвращает объяснительные студентов курируемых групп с указанным статусом, по умолчанию ожидающие рассмотрения (pending), старые - первыми. Пользователям с правом attendance.read.all возвращаются объяснительные всех групп.
+// @Tags curator
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Статус: pending (по умолчанию), approved или rejected"
+// @Param group_id query int false "ID Группы"
+// @Success 200 {array} models.ExcuseRequest "Список объяснительных"
+// @Failure 400 {object} map[string]interface{} "Неверный статус"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/excuses [get]
+func GetCuratedExcuses(c *gin.Context, db *gorm.DB) {
+	status := c.DefaultQuery("status", models.ExcusePending)
+	if status != models.ExcusePending && status != models.ExcuseApproved && status != models.ExcuseRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected pending, approved or rejected"})
+		return
+	}
+
+	query := curatedExcuses(c, db).Where("status = ?", status)
+	if groupID := c.Query("group_id"); groupID != "" {
+		students := db.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).Select("id").Where("group_id = ?", groupID)
+		query = query.Where("student_id IN (?)", students)
+	}
+
+	var excuses []models.ExcuseRequest
+	if err := query.Preload("Student.Group").Preload("ReviewedBy").Order("created_at").Find(&excuses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve excuses"})
+		return
+	}
+	c.JSON(http.StatusOK, excuses)
+}
+
+// GetCuratedExcuseFile godoc
+// @Summary Скачать документ объяснительной
+// @Description Возвращает файл, приложенный к объяснительной студента курируемой группы.
+// @Tags curator
+// @Produce  application/pdf
+// @Produce  image/jpeg
+// @Security BearerAuth
+// @Param id path int true "ID Объяснительной"
+// @Success 200 {file} file "Документ"
+// @Failure 404 {object} map[string]interface{} "Объяснительная или файл не найдены"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/excuses/{id}/file [get]
+func GetCuratedExcuseFile(c *gin.Context, db *gorm.DB, store storage.Storage) {
+	var excuse models.ExcuseRequest
+	if err := curatedExcuses(c, db).First(&excuse, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Excuse not found"})
+		return
+	}
+	serveExcuseFile(c, store, excuse)
+}
+
+// reviewExcuse moves an excuse of a curated student to status and lets the
+// student know by email.
+func reviewExcuse(c *gin.Context, db *gorm.DB, m mailer.Mailer, status string) {
+	var req ExcuseReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var excuse models.ExcuseRequest
+	if err := curatedExcuses(c, db).Preload("Student").First(&excuse, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Excuse not found"})
+		return
+	}
+	if excuse.Status == status {
+		c.JSON(http.StatusOK, excuse)
+		return
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	err := db.Model(&excuse).Updates(map[string]interface{}{
+		"status":         status,
+		"reviewed_by_id": currentUserID(c),
+		"reviewed_at":    time.Now(),
+		"review_comment": comment,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review excuse"})
+		return
+	}
+
+	period := excuse.StartDate.Format("02.01.2006") + " - " + excuse.EndDate.Format("02.01.2006")
+	msg := mailer.Message{To: excuse.Student.Email}
+	if status == models.ExcuseApproved {
+		msg.Subject = "Объяснительная принята"
+		msg.Body = fmt.Sprintf("Здравствуйте, %s!\n\nВаша объяснительная за период %s принята. Пропуски в эти дни засчитаны как пропуски по уважительной причине.\n", excuse.Student.Name, period)
+	} else {
+		msg.Subject = "Объяснительная отклонена"
+		msg.Body = fmt.Sprintf("Здравствуйте, %s!\n\nВаша объяснительная за период %s отклонена.\n", excuse.Student.Name, period)
+	}
+	if comment != "" {
+		msg.Body += "\nКомментарий: " + comment + "\n"
+	}
+	sendMail(m, msg)
+
+	if err := db.Preload("Student").Preload("ReviewedBy").First(&excuse, excuse.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve excuse"})
+		return
+	}
+	c.JSON(http.StatusOK, excuse)
+}
+
+// ApproveExcuse godoc
+// @Summary Одобрить объяснительную
+// @Description Куратор группы или администратор одобряет объяснительную (в том числе ранее отклоненную). Пропуски студента в указанном периоде начинают считаться пропусками по уважительной причине (excused) во всех отчетах о посещаемости. Студенту отправляется email.
+// @Tags curator
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Объяснительной"
+// @Param review body ExcuseReviewRequest false "Комментарий"
+// @Success 200 {object} models.ExcuseRequest "Одобренная объяснительная"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Объяснительная не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/excuses/{id}/approve [post]
+func ApproveExcuse(c *gin.Context, db *gorm.DB, m mailer.Mailer) {
+	reviewExcuse(c, db, m, models.ExcuseApproved)
+}
+
+// RejectExcuse godoc
+// @Summary Отклонить объяснительную
+// @Description Куратор группы или администратор отклоняет объяснительную, в том числе ранее одобренную, после чего пропуски снова считаются неуважительными. Студенту отправляется email.
+// @Tags curator
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Объяснительной"
+// @Param review body ExcuseReviewRequest false "Комментарий"
+// @Success 200 {object} models.ExcuseRequest "Отклоненная объяснительная"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Объяснительная не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/excuses/{id}/reject [post]
+func RejectExcuse(c *gin.Context, db *gorm.DB, m mailer.Mailer) {
+	reviewExcuse(c, db, m, models.ExcuseRejected)
+}
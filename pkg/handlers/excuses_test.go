@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"student-attendance-app/pkg/mailer"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCuratedExcusesScope(t *testing.T) {
+	db := newTestDB(t)
+	f := newCuratorFixture(t, db)
+	staff := models.User{Identifier: "teacher002", Password: "x", Name: "Staff", Email: "teacher002@example.com", Role: "teacher", GroupID: &f.group.ID}
+	create(t, db, &staff)
+
+	day := time.Date(2025, 10, 28, 0, 0, 0, 0, time.UTC)
+	excuse := func(student models.User) *models.ExcuseRequest {
+		return &models.ExcuseRequest{StudentID: student.ID, StartDate: day, EndDate: day, FileKey: "key", FileName: "note.pdf", ContentType: "application/pdf", FileSize: 1}
+	}
+	own, foreign, staffs := excuse(f.students[0]), excuse(f.otherStudent), excuse(staff)
+	create(t, db, own, foreign, staffs)
+	permissions := map[string]bool{rbac.AttendanceReadGroup: true, rbac.ExcusesReview: true}
+
+	status, body := curatorRequest(t, f.curator.ID, permissions, 0, nil, func(c *gin.Context) { GetCuratedExcuses(c, db) })
+	if status != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	var excuses []models.ExcuseRequest
+	if err := json.Unmarshal(body, &excuses); err != nil {
+		t.Fatalf("failed to decode excuses: %v", err)
+	}
+	// Neither another group's students nor staff of the curated group
+	if len(excuses) != 1 || excuses[0].ID != own.ID {
+		t.Errorf("excuses = %s, want only the one of %s", body, f.students[0].Name)
+	}
+
+	approve := func(c *gin.Context) { ApproveExcuse(c, db, &mailer.LogMailer{}) }
+	for _, other := range []*models.ExcuseRequest{foreign, staffs} {
+		if status, body := curatorRequest(t, f.curator.ID, permissions, other.ID, nil, approve); status != http.StatusNotFound {
+			t.Errorf("approving excuse %d got status %d, want %d: %s", other.ID, status, http.StatusNotFound, body)
+		}
+		if db.First(other, other.ID); other.Status != models.ExcusePending {
+			t.Errorf("excuse %d status = %q, want it still pending", other.ID, other.Status)
+		}
+	}
+	if status, body := curatorRequest(t, f.curator.ID, permissions, own.ID, nil, approve); status != http.StatusOK {
+		t.Errorf("approving own group's excuse got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	if db.First(own, own.ID); own.Status != models.ExcuseApproved {
+		t.Errorf("own group's excuse status = %q, want %q", own.Status, models.ExcuseApproved)
+	}
+}
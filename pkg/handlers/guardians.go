@@ -0,0 +1,555 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/auth"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/mailer"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Guardian invites are valid for a week, parents do not always read their
+// mail daily.
+const guardianInviteTTL = 7 * 24 * time.Hour
+
+var (
+	errInvalidGuardianInvite = errors.New("invalid or expired guardian invite")
+	errGuardianIsStudent     = errors.New("a student cannot be their own guardian")
+	errGuardianEmailMismatch = errors.New("guardian invite was sent to another email")
+)
+
+type GuardianInviteRequest struct {
+	Email string `json:"email" binding:"required" example:"parent@example.com"`
+}
+
+type GuardianLinkRequest struct {
+	GuardianID uint `json:"guardian_id" binding:"required" example:"12"`
+}
+
+type GuardianInviteTokenRequest struct {
+	Token string `json:"token" binding:"required" example:"mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"`
+}
+
+type AcceptGuardianInviteRequest struct {
+	Token      string `json:"token" binding:"required" example:"mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"`
+	Identifier string `json:"identifier" binding:"required" example:"parent001"`
+	Name       string `json:"name" binding:"required" example:"Parent Name"`
+	Password   string `json:"password" binding:"required" example:"securepassword"`
+}
+
+// linkedStudentIDs selects the IDs of the students the current user is a
+// guardian of.
+func linkedStudentIDs(c *gin.Context, db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.GuardianLink{}).
+		Select("student_id").
+		Where("guardian_id = ?", currentUserID(c))
+}
+
+// findLinkedStudent loads the student from the id path parameter if the
+// current user is their guardian. It writes a 400, 404 or 500 response and
+// returns false otherwise; other students are reported as not found.
+func findLinkedStudent(c *gin.Context, db *gorm.DB) (models.User, bool) {
+	var student models.User
+	studentID, ok := paramUint(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return student, false
+	}
+	err := db.Preload("Group").Where("id IN (?)", linkedStudentIDs(c, db)).First(&student, studentID).Error
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return student, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve student"})
+		return student, false
+	}
+	return student, true
+}
+
+// linkGuardian makes the user a guardian of the student and gives them the
+// guardian role. Linking twice is a no-op.
+func linkGuardian(tx *gorm.DB, guardianID, studentID, createdByID uint) error {
+	if guardianID == studentID {
+		return errGuardianIsStudent
+	}
+	link := models.GuardianLink{GuardianID: guardianID, StudentID: studentID, CreatedByID: createdByID}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&link).Error; err != nil {
+		return err
+	}
+	return rbac.AssignRole(tx, guardianID, rbac.RoleGuardian)
+}
+
+// claimGuardianInvite marks an invite as accepted and returns it. An invite
+// can only be accepted once, even by concurrent requests.
+func claimGuardianInvite(tx *gorm.DB, token string) (models.GuardianInvite, error) {
+	var invite models.GuardianInvite
+	err := tx.Where("token_hash = ?", auth.HashToken(token)).First(&invite).Error
+	if err == gorm.ErrRecordNotFound {
+		return invite, errInvalidGuardianInvite
+	}
+	if err != nil {
+		return invite, err
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.GuardianInvite{}).
+		Where("id = ? AND accepted_at IS NULL AND expires_at > ?", invite.ID, now).
+		Update("accepted_at", now)
+	if result.Error != nil {
+		return invite, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return invite, errInvalidGuardianInvite
+	}
+	return invite, nil
+}
+
+// inviteGuardian mails a guardian invite for the student to the address in
+// the request and writes the response.
+func inviteGuardian(c *gin.Context, db *gorm.DB, cfg *config.Config, m mailer.Mailer, student models.User) {
+	var req GuardianInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	email := strings.TrimSpace(req.Email)
+	if !mailer.ValidAddress(email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
+
+	token, err := auth.NewToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+	invite := models.GuardianInvite{
+		StudentID:   student.ID,
+		Email:       email,
+		CreatedByID: currentUserID(c),
+		TokenHash:   auth.HashToken(token),
+		ExpiresAt:   time.Now().Add(guardianInviteTTL),
+	}
+	if err := db.Create(&invite).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+
+	sendMail(m, mailer.Message{
+		To:      email,
+		Subject: "Приглашение в систему учета посещаемости",
+		Body: fmt.Sprintf("Здравствуйте!\n\nВас пригласили следить за посещаемостью студента %s. Чтобы создать аккаунт родителя или привязать студента к существующему аккаунту, откройте ссылку:\n%s\n\nСсылка действует 7 дней.\n",
+			student.Name, frontendLink(cfg, "/guardian-invite", token)),
+	})
+	c.JSON(http.StatusOK, invite)
+}
+
+// AcceptGuardianInvite godoc
+// @Summary Создать аккаунт родителя по приглашению
+// @Description Создает аккаунт родителя с email из приглашения и привязывает к нему студента. Email считается подтвержденным. Если аккаунт с этим email уже существует, нужно войти в него и принять приглашение через /api/guardian/invites/accept.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   request body AcceptGuardianInviteRequest true "Токен из письма и данные аккаунта"
+// @Success 200 {object} map[string]interface{} "Аккаунт создан"
+// @Failure 400 {object} map[string]interface{} "Неверный или просроченный токен (code: invalid_token)"
+// @Failure 409 {object} map[string]interface{} "Аккаунт с таким email уже существует (code: account_exists) или логин занят"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /auth/guardian-invite/accept [post]
+func AcceptGuardianInvite(c *gin.Context, db *gorm.DB) {
+	var req AcceptGuardianInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 14)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	errAccountExists := errors.New("account exists")
+	err = db.Transaction(func(tx *gorm.DB) error {
+		invite, err := claimGuardianInvite(tx, req.Token)
+		if err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.User{}).Where("LOWER(email) = LOWER(?)", invite.Email).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAccountExists
+		}
+
+		// The invite was mailed to this address, so it counts as verified
+		now := time.Now()
+		guardian := models.User{
+			Identifier:      req.Identifier,
+			Password:        string(hashedPassword),
+			Name:            req.Name,
+			Email:           invite.Email,
+			Role:            rbac.RoleGuardian,
+			EmailVerifiedAt: &now,
+		}
+		if err := tx.Create(&guardian).Error; err != nil {
+			return err
+		}
+		return linkGuardian(tx, guardian.ID, invite.StudentID, invite.CreatedByID)
+	})
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Guardian account created successfully, you can log in now"})
+	case err == errInvalidGuardianInvite:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite", "code": errCodeInvalidToken})
+	case err == errAccountExists:
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists, log in to accept the invite", "code": errCodeAccountExists})
+	case isDuplicateKeyError(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "Identifier already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+	}
+}
+
+// LinkGuardianInvite godoc
+// @Summary Принять приглашение родителя
+// @Description Привязывает студента из приглашения к аккаунту залогиненного пользователя и выдает ему роль guardian. Email аккаунта должен совпадать с адресом, на который отправлено приглашение.
+// @Tags guardian
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   request body GuardianInviteTokenRequest true "Токен из письма"
+// @Success 200 {object} map[string]interface{} "Студент привязан"
+// @Failure 400 {object} map[string]interface{} "Неверный или просроченный токен (code: invalid_token)"
+// @Failure 403 {object} map[string]interface{} "Приглашение отправлено на другой email (code: invite_email_mismatch)"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/guardian/invites/accept [post]
+func LinkGuardianInvite(c *gin.Context, db *gorm.DB) {
+	var req GuardianInviteTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, currentUserID(c)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+		return
+	}
+
+	// The invite stays unclaimed when the transaction fails, so its addressee
+	// can still use it
+	err := db.Transaction(func(tx *gorm.DB) error {
+		invite, err := claimGuardianInvite(tx, req.Token)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(strings.TrimSpace(invite.Email), strings.TrimSpace(user.Email)) {
+			return errGuardianEmailMismatch
+		}
+		return linkGuardian(tx, user.ID, invite.StudentID, invite.CreatedByID)
+	})
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Student linked successfully"})
+	case err == errInvalidGuardianInvite:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite", "code": errCodeInvalidToken})
+	case err == errGuardianEmailMismatch:
+		c.JSON(http.StatusForbidden, gin.H{"error": "This invite was sent to another email address", "code": errCodeInviteEmailMismatch})
+	case err == errGuardianIsStudent:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+	}
+}
+
+// GetGuardianStudents godoc
+// @Summary Получить привязанных студентов
+// @Description Возвращает студентов, родителем которых является залогиненный пользователь.
+// @Tags guardian
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} models.User "Список студентов"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/guardian/students [get]
+func GetGuardianStudents(c *gin.Context, db *gorm.DB) {
+	var students []models.User
+	if err := db.Preload("Group").Where("id IN (?)", linkedStudentIDs(c, db)).Order("name").Find(&students).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve students"})
+		return
+	}
+	c.JSON(http.StatusOK, students)
+}
+
+// GetGuardianStudentAttendance godoc
+// @Summary Получить посещаемость привязанного студента
+// @Description Возвращает записи о посещаемости студента, как GET /api/student/attendance, опционально только за указанный семестр. Доступно только родителям студента.
+// @Tags guardian
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Студента"
+// @Param term_id query int false "ID Семестра"
+// @Success 200 {array} models.Attendance "Список записей о посещаемости"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или семестр не найден"
+// @Failure 404 {object} map[string]interface{} "Студент не найден или не привязан"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/guardian/students/{id}/attendance [get]
+func GetGuardianStudentAttendance(c *gin.Context, db *gorm.DB) {
+	student, ok := findLinkedStudent(c, db)
+	if !ok {
+		return
+	}
+	query, ok := scopeToTerm(c, db)
+	if !ok {
+		return
+	}
+
+	var attendance []models.Attendance
+	if err := query.Preload("Lesson").Preload("Session").
+		Where("student_id = ? AND student_id IN (?)", student.ID, linkedStudentIDs(c, db)).
+		Find(&attendance).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance records"})
+		return
+	}
+	c.JSON(http.StatusOK, attendance)
+}
+
+// GetGuardianStudentStats godoc
+// @Summary Получить статистику посещаемости привязанного студента
+// @Description Возвращает число проведенных занятий группы студента, посещенных и пропущенных, и процент посещаемости, опционально только за указанный семестр. Доступно только родителям студента.
+// @Tags guardian
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Студента"
+// @Param term_id query int false "ID Семестра"
+// @Success 200 {object} AttendanceStats "Статистика посещаемости"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или семестр не найден"
+// @Failure 404 {object} map[string]interface{} "Студент не найден или не привязан"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/guardian/students/{id}/stats [get]
+func GetGuardianStudentStats(c *gin.Context, db *gorm.DB) {
+	student, ok := findLinkedStudent(c, db)
+	if !ok {
+		return
+	}
+	sessionIDs, ok := heldSessions(c, db)
+	if !ok {
+		return
+	}
+
+	stats, err := attendanceStats(db, []models.User{student}, sessionIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute attendance statistics"})
+		return
+	}
+	c.JSON(http.StatusOK, stats[0])
+}
+
+// GetMyGuardians godoc
+// @Summary Получить моих родителей
+// @Description Возвращает родителей, которым открыт доступ к посещаемости залогиненного студента.
+// @Tags student
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} models.GuardianLink "Список привязок"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/guardians [get]
+func GetMyGuardians(c *gin.Context, db *gorm.DB) {
+	var links []models.GuardianLink
+	if err := db.Preload("Guardian").Where("student_id = ?", currentUserID(c)).Order("created_at").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve guardians"})
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+// InviteMyGuardian godoc
+// @Summary Пригласить родителя
+// @Description Отправляет на указанный email приглашение, по которому родитель создает аккаунт или привязывает студента к существующему и получает доступ к его посещаемости только для чтения.
+// @Tags student
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param   request body GuardianInviteRequest true "Email родителя"
+// @Success 200 {object} models.GuardianInvite "Отправленное приглашение"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/guardians/invites [post]
+func InviteMyGuardian(c *gin.Context, db *gorm.DB, cfg *config.Config, m mailer.Mailer) {
+	var student models.User
+	if err := db.First(&student, currentUserID(c)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+	inviteGuardian(c, db, cfg, m, student)
+}
+
+// RemoveMyGuardian godoc
+// @Summary Отключить доступ родителя
+// @Description Удаляет привязку родителя к залогиненному студенту.
+// @Tags student
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Привязки"
+// @Success 200 {object} map[string]interface{} "Привязка удалена"
+// @Failure 404 {object} map[string]interface{} "Привязка не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/student/guardians/{id} [delete]
+func RemoveMyGuardian(c *gin.Context, db *gorm.DB) {
+	result := db.Where("id = ? AND student_id = ?", c.Param("id"), currentUserID(c)).Delete(&models.GuardianLink{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove guardian"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guardian link not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Guardian removed successfully"})
+}
+
+// findStudent loads the student from the id path parameter for the admin
+// guardian handlers. It writes a 400 or 404 response and returns false
+// otherwise.
+func findStudent(c *gin.Context, db *gorm.DB) (models.User, bool) {
+	var student models.User
+	if err := db.First(&student, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return student, false
+	}
+	if student.Role != rbac.RoleStudent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a student"})
+		return student, false
+	}
+	return student, true
+}
+
+// AdminGetStudentGuardians godoc
+// @Summary Получить родителей студента (Админ)
+// @Description Возвращает родителей, привязанных к студенту.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Студента"
+// @Success 200 {array} models.GuardianLink "Список привязок"
+// @Failure 400 {object} map[string]interface{} "Пользователь не является студентом"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id}/guardians [get]
+func AdminGetStudentGuardians(c *gin.Context, db *gorm.DB) {
+	student, ok := findStudent(c, db)
+	if !ok {
+		return
+	}
+
+	var links []models.GuardianLink
+	if err := db.Preload("Guardian").Where("student_id = ?", student.ID).Order("created_at").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve guardians"})
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+// AdminInviteGuardian godoc
+// @Summary Пригласить родителя студента (Админ)
+// @Description Отправляет на указанный email приглашение стать родителем студента.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Студента"
+// @Param   request body GuardianInviteRequest true "Email родителя"
+// @Success 200 {object} models.GuardianInvite "Отправленное приглашение"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или пользователь не является студентом"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id}/guardians/invites [post]
+func AdminInviteGuardian(c *gin.Context, db *gorm.DB, cfg *config.Config, m mailer.Mailer) {
+	student, ok := findStudent(c, db)
+	if !ok {
+		return
+	}
+	inviteGuardian(c, db, cfg, m, student)
+}
+
+// AdminLinkGuardian godoc
+// @Summary Привязать родителя к студенту (Админ)
+// @Description Привязывает существующий аккаунт родителем студента и выдает ему роль guardian.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Студента"
+// @Param   request body GuardianLinkRequest true "ID Родителя"
+// @Success 200 {object} map[string]interface{} "Родитель привязан"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос, пользователь не является студентом или родитель не найден"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/users/{id}/guardians [post]
+func AdminLinkGuardian(c *gin.Context, db *gorm.DB) {
+	student, ok := findStudent(c, db)
+	if !ok {
+		return
+	}
+
+	var req GuardianLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var guardian models.User
+	if err := db.First(&guardian, req.GuardianID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Guardian not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return linkGuardian(tx, guardian.ID, student.ID, currentUserID(c))
+	})
+	if err == errGuardianIsStudent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link guardian"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Guardian linked successfully"})
+}
+
+// AdminUnlinkGuardian godoc
+// @Summary Отвязать родителя (Админ)
+// @Description Удаляет привязку родителя к студенту. Роль guardian у аккаунта сохраняется.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Привязки"
+// @Success 200 {object} map[string]interface{} "Привязка удалена"
+// @Failure 404 {object} map[string]interface{} "Привязка не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/guardian-links/{id} [delete]
+func AdminUnlinkGuardian(c *gin.Context, db *gorm.DB) {
+	result := db.Delete(&models.GuardianLink{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove guardian"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guardian link not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Guardian removed successfully"})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"student-attendance-app/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGuardianScope(t *testing.T) {
+	db := newTestDB(t)
+	f := newAttendanceFixture(t, db)
+	guardian := models.User{Identifier: "parent001", Password: "x", Name: "Parent", Email: "parent001@example.com", Role: "guardian"}
+	create(t, db, &guardian)
+	create(t, db,
+		&models.GuardianLink{GuardianID: guardian.ID, StudentID: f.student.ID, CreatedByID: f.student.ID},
+		&models.Attendance{LessonID: f.lesson.ID, SessionID: &f.session.ID, StudentID: f.student.ID, SubmittedAt: time.Now()},
+	)
+
+	// The outsider attended a lesson of their own group
+	otherLesson := models.Lesson{Name: "Physics", Day: "Понедельник", Time: "09:00-10:30", GroupID: f.outsider.GroupID}
+	create(t, db, &otherLesson)
+	otherSession := models.LessonSession{LessonID: otherLesson.ID, Date: f.session.Date, StartsAt: f.session.StartsAt, EndsAt: f.session.EndsAt}
+	create(t, db, &otherSession)
+	create(t, db, &models.Attendance{LessonID: otherLesson.ID, SessionID: &otherSession.ID, StudentID: f.outsider.ID, SubmittedAt: time.Now()})
+
+	c, w := newTestContext(t, guardian.ID, nil)
+	GetGuardianStudents(c, db)
+	var students []models.User
+	if err := json.Unmarshal(w.Body.Bytes(), &students); err != nil {
+		t.Fatalf("failed to decode students: %v", err)
+	}
+	if len(students) != 1 || students[0].ID != f.student.ID {
+		t.Errorf("students = %s, want only %s", w.Body, f.student.Name)
+	}
+
+	attendance := func(studentID uint) (int, []models.Attendance) {
+		t.Helper()
+		c, w := newTestContext(t, guardian.ID, nil)
+		c.Params = gin.Params{{Key: "id", Value: strconv.Itoa(int(studentID))}}
+		GetGuardianStudentAttendance(c, db)
+		var records []models.Attendance
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+				t.Fatalf("failed to decode attendance: %v", err)
+			}
+		}
+		return w.Code, records
+	}
+
+	status, records := attendance(f.student.ID)
+	if status != http.StatusOK || len(records) != 1 || records[0].StudentID != f.student.ID {
+		t.Errorf("linked student got status %d and %+v, want their one record", status, records)
+	}
+	if status, _ := attendance(f.outsider.ID); status != http.StatusNotFound {
+		t.Errorf("other student got status %d, want %d", status, http.StatusNotFound)
+	}
+
+	c, w = newTestContext(t, guardian.ID, nil)
+	c.Params = gin.Params{{Key: "id", Value: strconv.Itoa(int(f.outsider.ID))}}
+	GetGuardianStudentStats(c, db)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("stats of other student got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -1,18 +1,25 @@
 package handlers
 
 import (
-	"math/rand"
+	"errors"
+	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"student-attendance-app/pkg/auth"
+	"student-attendance-app/pkg/codes"
 	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/mailer"
 	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"student-attendance-app/pkg/schedule"
+	"student-attendance-app/pkg/storage"
+	"student-attendance-app/pkg/throttle"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LoginRequest struct {
@@ -25,7 +32,13 @@ type RegisterRequest struct {
 	Password   string `json:"password" binding:"required" example:"securepassword"`
 	Name       string `json:"name" binding:"required" example:"New Student"`
 	Email      string `json:"email" binding:"required" example:"new@example.com"`
-	GroupID    uint   `json:"group_id" binding:"required" example:"1"`
+	GroupID    uint   `json:"group_id" example:"1"`             // Required unless registering with an invite code
+	InviteCode string `json:"invite_code" example:"K7M2QX9P4A"` // Required with the invite registration policy
+}
+
+type GenerateCodeRequest struct {
+	LessonID uint   `json:"lesson_id" binding:"required" example:"1"`
+	Mode     string `json:"mode" example:"rotating"` // 'static' or 'rotating', defaults to the server setting
 }
 
 type SubmitAttendanceRequest struct {
@@ -33,11 +46,43 @@ type SubmitAttendanceRequest struct {
 	Code     string `json:"code" binding:"required" example:"12345"`
 }
 
+// isDuplicateKeyError reports whether err is a Postgres unique constraint violation.
+func isDuplicateKeyError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint")
+}
+
+// Machine-readable error codes returned in the "code" field of error
+// responses, so the frontend can explain a rejection.
+const (
+	errCodeNotInLessonGroup = "not_in_lesson_group"
+	errCodeInvalidCheckin   = "invalid_checkin"
+	errCodeTooManyAttempts  = "too_many_attempts"
+	errCodeLoginLocked      = "login_locked"
+
+	errCodeInvalidRefreshToken = "invalid_refresh_token"
+	errCodeRefreshTokenReused  = "refresh_token_reused"
+	errCodeInvalidToken        = "invalid_token"
+	errCodeEmailNotVerified    = "email_not_verified"
+	errCodeAccountPending      = "account_pending"
+	errCodeAccountRejected     = "account_rejected"
+	errCodeInviteRequired      = "invite_required"
+	errCodeInvalidInvite       = "invalid_invite"
+	errCodeAccountExists       = "account_exists"
+	errCodeInviteEmailMismatch = "invite_email_mismatch"
+)
+
+// currentUserID returns the ID of the authenticated user set by AuthMiddleware.
+func currentUserID(c *gin.Context) uint {
+	userID, _ := c.Get("userID")
+	id, _ := userID.(float64)
+	return uint(id)
+}
+
 // Auth Handlers
 
 // Login godoc
 // @Summary Вход пользователя
-// @Description Аутентифицирует пользователя и возвращает JWT токен.
+// @Description Аутентифицирует пользователя и возвращает короткоживущий JWT токен (token, срок в секундах в expires_in) и одноразовый refresh_token для его обновления через /auth/refresh. После 5 неудачных попыток для логина или 20 с одного IP-адреса вход временно блокируется, время блокировки удваивается с каждой следующей ошибкой (до 15 минут).
 // @Tags auth
 // @Accept  json
 // @Produce  json
@@ -45,53 +90,108 @@ type SubmitAttendanceRequest struct {
 // @Success 200 {object} map[string]interface{} "Успешный вход"
 // @Failure 400 {object} map[string]interface{} "Неверный запрос"
 // @Failure 401 {object} map[string]interface{} "Неверные учетные данные"
+// @Failure 403 {object} map[string]interface{} "Email не подтвержден или аккаунт не одобрен (code: email_not_verified, account_pending, account_rejected)"
+// @Failure 429 {object} map[string]interface{} "Слишком много неудачных попыток (code: login_locked, retry_after в секундах)"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /auth/login [post]
-func Login(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+func Login(c *gin.Context, db *gorm.DB, cfg *config.Config, throttler *throttle.Throttler) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Refuse locked out identifiers and addresses before checking the password
+	now := time.Now()
+	identifierKey := throttle.IdentifierKey(req.Identifier)
+	ipKey := throttle.IPKey(c.ClientIP())
+	lockedUntil, err := throttler.LockedUntil(now, identifierKey, ipKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return
+	}
+	if lockedUntil != nil {
+		respondLocked(c, errCodeLoginLocked, "Too many failed logins, try again later", *lockedUntil, now)
+		return
+	}
+
 	var user models.User
-	if err := db.First(&user, "identifier = ?", req.Identifier).Error; err != nil {
+	err = db.First(&user, "identifier = ?", req.Identifier).Error
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+	}
+	if err != nil {
+		lockedUntil, failErr := throttler.Fail(now, identifierKey, ipKey)
+		if failErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+			return
+		}
+		log.Printf("auth: failed login for %q from %s", req.Identifier, c.ClientIP())
+		if lockedUntil != nil {
+			respondLocked(c, errCodeLoginLocked, "Too many failed logins, try again later", *lockedUntil, now)
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	if user.EmailVerifiedAt == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please confirm your email address first", "code": errCodeEmailNotVerified})
+		return
+	}
+	if user.Status == models.UserPending {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your account is waiting for approval by an administrator", "code": errCodeAccountPending})
+		return
+	}
+	if user.Status == models.UserRejected {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your registration has been rejected", "code": errCodeAccountRejected})
 		return
 	}
 
-	token, err := auth.GenerateJWT(user, cfg)
+	// Only the account's counter is reset: a working password for one account
+	// must not clear the failures an address collected against others
+	if err := throttler.Reset(identifierKey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check login attempts"})
+		return
+	}
+
+	tokens, err := auth.StartSession(db, user, c.Request.UserAgent(), c.ClientIP(), cfg)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token, "user": user})
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          user,
+	})
 }
 
 // Register godoc
 // @Summary Регистрация нового студента
-// @Description Создает нового пользователя-студента.
+// @Description Создает нового пользователя-студента и отправляет на его email ссылку для подтверждения. Войти можно только после подтверждения. В зависимости от политики регистрации (GET /auth/registration-policy) группа выбирается студентом (open), определяется кодом приглашения (invite) или аккаунт ждет одобрения администратора (approval, status: pending).
 // @Tags auth
 // @Accept  json
 // @Produce  json
 // @Param   user body RegisterRequest true "Данные для регистрации пользователя"
 // @Success 200 {object} map[string]interface{} "Пользователь успешно зарегистрирован"
-// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или код приглашения (code: invite_required, invalid_invite)"
 // @Failure 409 {object} map[string]interface{} "Конфликт (идентификатор или email уже существует)"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /auth/register [post]
-func Register(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+func Register(c *gin.Context, db *gorm.DB, cfg *config.Config, m mailer.Mailer) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	req.Email = strings.TrimSpace(req.Email)
+	if !mailer.ValidAddress(req.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 14)
 	if err != nil {
@@ -99,9 +199,24 @@ func Register(c *gin.Context, db *gorm.DB, cfg *config.Config) {
 		return
 	}
 
+	// With invites the code decides the group, otherwise the student picks it
+	var invite models.GroupInvite
+	groupID := req.GroupID
+	if cfg.RegistrationPolicy == config.RegistrationInvite {
+		if strings.TrimSpace(req.InviteCode) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An invite code is required to register", "code": errCodeInviteRequired})
+			return
+		}
+		if err := db.Where("code = ?", normalizeInviteCode(req.InviteCode)).First(&invite).Error; err != nil || !inviteUsable(invite, time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite code", "code": errCodeInvalidInvite})
+			return
+		}
+		groupID = invite.GroupID
+	}
+
 	// Ensure group exists
 	var group models.Group
-	if err := db.First(&group, req.GroupID).Error; err != nil {
+	if groupID == 0 || db.First(&group, groupID).Error != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
 		return
 	}
@@ -112,11 +227,29 @@ func Register(c *gin.Context, db *gorm.DB, cfg *config.Config) {
 		Name:       req.Name,
 		Email:      req.Email,
 		Role:       "student",
-		GroupID:    &req.GroupID,
+		GroupID:    &group.ID,
+	}
+	if cfg.RegistrationPolicy == config.RegistrationApproval {
+		user.Status = models.UserPending
 	}
 
-	if err := db.Create(&user).Error; err != nil {
-		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if invite.ID != 0 {
+			if err := useInvite(tx, invite.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return rbac.AssignRole(tx, user.ID, rbac.RoleStudent)
+	})
+	if err == errInviteUsedUp {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite code", "code": errCodeInvalidInvite})
+		return
+	}
+	if err != nil {
+		if isDuplicateKeyError(err) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Identifier or email already exists"})
 			return
 		}
@@ -124,7 +257,16 @@ func Register(c *gin.Context, db *gorm.DB, cfg *config.Config) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User registered successfully"})
+	if err := mailEmailVerification(db, cfg, m, user); err != nil {
+		// The account exists, the user can ask for another link
+		log.Printf("auth: failed to issue email verification token for user %d: %v", user.ID, err)
+	}
+
+	if user.Status == models.UserPending {
+		c.JSON(http.StatusOK, gin.H{"message": "User registered successfully, check your email to confirm the address. An administrator has to approve the account before you can log in", "status": user.Status})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User registered successfully, check your email to confirm the address", "status": models.UserActive})
 }
 
 // GetGroups godoc
@@ -148,21 +290,42 @@ func GetGroups(c *gin.Context, db *gorm.DB) {
 
 // GetLessons godoc
 // @Summary Получить занятия
-// @Description Возвращает список занятий. Для студентов - занятия их группы. Для преподавателей/администраторов - все занятия.
+// @Description Возвращает расписание на текущую неделю (или неделю, содержащую date): занятия текущего семестра и занятия без семестра, с учетом числителя/знаменателя. Для пользователей без прав lessons.teach и lessons.manage (студентов) - занятия их группы, для остальных - все занятия.
 // @Tags lessons
 // @Produce  json
 // @Security BearerAuth
+// @Param date query string false "Любой день нужной недели (YYYY-MM-DD)"
 // @Success 200 {array} models.Lesson "Список занятий"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
 // @Failure 404 {object} map[string]interface{} "Пользователь не найден"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /api/lessons [get]
 func GetLessons(c *gin.Context, db *gorm.DB) {
-	userRole, _ := c.Get("userRole")
 	userID, _ := c.Get("userID")
 
+	day := time.Now()
+	if date := c.Query("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidDate.Error()})
+			return
+		}
+		day = parsed
+	}
+
 	var lessons []models.Lesson
 
-	if userRole == "student" {
+	// Only the current term's timetable is shown; lessons not bound to any
+	// term are permanent and always shown.
+	term, err := schedule.CurrentTerm(db, day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to determine current term"})
+		return
+	}
+	query := lessonsInTerm(db, term)
+
+	// Users who neither teach nor manage lessons see their group's timetable
+	if !rbac.Has(c, rbac.LessonsTeach, rbac.LessonsManage) {
 		var currentUser models.User
 		if err := db.First(&currentUser, userID).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
@@ -175,18 +338,74 @@ func GetLessons(c *gin.Context, db *gorm.DB) {
 		}
 
 		// Fetch lessons for the student's group
-		if err := db.Preload("Group").Where("group_id = ?", currentUser.GroupID).Find(&lessons).Error; err != nil {
+		if err := query.Preload("Group").Where("group_id = ?", currentUser.GroupID).Find(&lessons).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve lessons for group"})
 			return
 		}
 	} else {
 		// For teachers and admins, fetch all lessons
-		if err := db.Preload("Group").Order("day, time").Find(&lessons).Error; err != nil {
+		if err := query.Preload("Group").Order("day, time").Find(&lessons).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve all lessons"})
 			return
 		}
 	}
 
+	// Drop odd/even-week lessons that do not take place this week
+	thisWeek := make([]models.Lesson, 0, len(lessons))
+	for _, lesson := range lessons {
+		lessonTerm := term
+		if lesson.TermID == nil {
+			lessonTerm = nil
+		}
+		if schedule.MatchesWeek(lesson, lessonTerm, day) {
+			thisWeek = append(thisWeek, lesson)
+		}
+	}
+
+	c.JSON(http.StatusOK, thisWeek)
+}
+
+// lessonsInTerm scopes a lesson query to the timetable of term: its own
+// lessons plus the lessons not bound to any term.
+func lessonsInTerm(db *gorm.DB, term *models.Term) *gorm.DB {
+	if term == nil {
+		return db.Where("term_id IS NULL")
+	}
+	return db.Where("term_id IS NULL OR term_id = ?", term.ID)
+}
+
+// GetTeacherLessons godoc
+// @Summary Получить мои занятия
+// @Description Возвращает занятия текущего семестра, в которых залогиненный преподаватель назначен ведущим или ассистентом, а также занятия, права на которые ему делегированы. Пользователям с правом lessons.manage возвращаются все занятия семестра.
+// @Tags teacher
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {array} models.Lesson "Список занятий"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons [get]
+func GetTeacherLessons(c *gin.Context, db *gorm.DB) {
+	term, err := schedule.CurrentTerm(db, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to determine current term"})
+		return
+	}
+
+	// Holders of lessons.manage act as a teacher of every lesson
+	query := lessonsInTerm(db, term)
+	if !rbac.Has(c, rbac.LessonsManage) {
+		query = managedLessons(query, currentUserID(c))
+	}
+
+	var lessons []models.Lesson
+	err = query.
+		Preload("Group").
+		Preload("Teachers.Teacher").
+		Order("day, time").
+		Find(&lessons).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve lessons"})
+		return
+	}
 	c.JSON(http.StatusOK, lessons)
 }
 
@@ -194,63 +413,179 @@ func GetLessons(c *gin.Context, db *gorm.DB) {
 
 // SubmitAttendance godoc
 // @Summary Отметить посещаемость
-// @Description Студент отправляет код посещаемости для определенного занятия.
+// @Description Студент отправляет код посещаемости для определенного занятия. Меняющиеся коды проверяются по текущему временному окну. После 3 неверных кодов в одной сессии студент блокируется на время, которое удваивается с каждой следующей ошибкой (до 15 минут).
 // @Tags student
 // @Accept  json
 // @Produce  json
 // @Security BearerAuth
 // @Param   attendance body SubmitAttendanceRequest true "Данные для отметки посещаемости"
-// @Success 200 {object} map[string]interface{} "Посещаемость успешно отмечена"
+// @Success 200 {object} map[string]interface{} "Посещаемость успешно отмечена или уже была отмечена ранее (already_marked), со статусом present или late и minutes_late"
 // @Failure 400 {object} map[string]interface{} "Неверный или просроченный код"
+// @Failure 403 {object} map[string]interface{} "Студент не состоит в группе занятия (code: not_in_lesson_group)"
+// @Failure 404 {object} map[string]interface{} "Пользователь не найден"
+// @Failure 429 {object} map[string]interface{} "Слишком много неверных кодов (code: too_many_attempts, retry_after в секундах)"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /api/student/attendance [post]
-func SubmitAttendance(c *gin.Context, db *gorm.DB) {
+func SubmitAttendance(c *gin.Context, db *gorm.DB, cfg *config.Config) {
 	var req SubmitAttendanceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	userID, _ := c.Get("userID")
+	markAttendance(c, db, cfg, req.LessonID, req.Code, nil)
+}
 
-	// Validate code
-	var generatedCode models.GeneratedCode
-	err := db.Where("lesson_id = ? AND code = ? AND is_active = ? AND expires_at > ?", req.LessonID, req.Code, true, time.Now()).
-		Order("created_at desc").
-		First(&generatedCode).Error
+// markAttendance records the current student as present if code is the
+// lesson's active code. A non-nil sessionID additionally requires the code to
+// have been issued for that session.
+func markAttendance(c *gin.Context, db *gorm.DB, cfg *config.Config, lessonID uint, rawCode string, sessionID *uint) {
+	userID := currentUserID(c)
 
-	if err != nil {
+	// Only students of the lesson's group may mark attendance, even with a valid code
+	var lesson models.Lesson
+	if err := db.First(&lesson, lessonID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired code"})
 		return
 	}
+	var student models.User
+	if err := db.First(&student, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if lesson.GroupID == nil || student.GroupID == nil || *lesson.GroupID != *student.GroupID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a member of this lesson's group", "code": errCodeNotInLessonGroup})
+		return
+	}
+
+	// Alphanumeric codes are issued in upper case
+	code := strings.ToUpper(strings.TrimSpace(rawCode))
+
+	// Validate the code and save attendance in one transaction. The unique
+	// index on (session_id, student_id) makes repeated or concurrent
+	// submissions insert at most one row.
+	now := time.Now()
+	var attendance models.Attendance
+	alreadyMarked := false
+	var attemptSessionID uint
+	var lockedUntil *time.Time
+	wrongCode := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var generatedCode models.GeneratedCode
+		if err := tx.Preload("Session").Where("lesson_id = ? AND is_active = ? AND expires_at > ?", lessonID, true, now).
+			Order("created_at desc").
+			First(&generatedCode).Error; err != nil {
+			return err
+		}
+		if sessionID != nil && (generatedCode.SessionID == nil || *generatedCode.SessionID != *sessionID) {
+			return errInvalidCode
+		}
+		// Students who guessed wrong too often have to wait before trying again
+		if generatedCode.SessionID != nil {
+			attemptSessionID = *generatedCode.SessionID
+			var err error
+			if lockedUntil, err = codeLockedUntil(tx, attemptSessionID, student.ID, now); err != nil {
+				return err
+			}
+			if lockedUntil != nil {
+				return errCodeAttemptsLocked
+			}
+		}
+		if !codeMatches(cfg, lesson, generatedCode, code, now) {
+			wrongCode = true
+			return errInvalidCode
+		}
+
+		// Save attendance against the session the code was issued for
+		attendance = models.Attendance{
+			LessonID:    lessonID,
+			SessionID:   generatedCode.SessionID,
+			StudentID:   student.ID,
+			SubmittedAt: now,
+			Status:      models.AttendancePresent,
+			Source:      models.AttendanceSourceCode,
+		}
+		if generatedCode.SessionID != nil {
+			attendance.Status, attendance.MinutesLate = schedule.Lateness(generatedCode.Session, now, cfg.LateGracePeriod)
+		}
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "session_id"}, {Name: "student_id"}},
+			DoNothing: true,
+		}).Create(&attendance)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
 
-	// Save attendance
-	attendance := models.Attendance{
-		LessonID:    req.LessonID,
-		StudentID:   uint(userID.(float64)),
-		SubmittedAt: time.Now(),
+		alreadyMarked = true
+		return tx.Where("session_id = ? AND student_id = ?", generatedCode.SessionID, student.ID).First(&attendance).Error
+	})
+	if err == errCodeAttemptsLocked {
+		respondCodeLocked(c, *lockedUntil, now)
+		return
 	}
-	if err := db.Create(&attendance).Error; err != nil {
+	if wrongCode && attemptSessionID != 0 {
+		lockedUntil, recordErr := recordFailedCode(c, db, lessonID, attemptSessionID, student.ID, now)
+		if recordErr != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attendance"})
+			return
+		}
+		if lockedUntil != nil {
+			respondCodeLocked(c, *lockedUntil, now)
+			return
+		}
+	}
+	if (err == gorm.ErrRecordNotFound && !alreadyMarked) || err == errInvalidCode {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired code"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attendance"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Attendance marked successfully"})
+	if alreadyMarked {
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "Already marked at " + attendance.SubmittedAt.Local().Format("15:04"),
+			"already_marked": true,
+			"submitted_at":   attendance.SubmittedAt,
+			"status":         attendance.Status,
+			"minutes_late":   attendance.MinutesLate,
+			"source":         attendance.Source,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Attendance marked successfully",
+		"already_marked": false,
+		"submitted_at":   attendance.SubmittedAt,
+		"status":         attendance.Status,
+		"minutes_late":   attendance.MinutesLate,
+		"source":         attendance.Source,
+	})
 }
 
 // GetStudentAttendance godoc
 // @Summary Получить записи о посещаемости студента
-// @Description Получает все записи о посещаемости для залогиненного студента.
+// @Description Получает все записи о посещаемости для залогиненного студента, опционально только за указанный семестр. Каждая запись содержит статус (present, late, absent или excused) и число минут опоздания.
 // @Tags student
 // @Produce  json
 // @Security BearerAuth
+// @Param term_id query int false "ID Семестра"
 // @Success 200 {array} models.Attendance "Список записей о посещаемости"
+// @Failure 400 {object} map[string]interface{} "Семестр не найден"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /api/student/attendance [get]
 func GetStudentAttendance(c *gin.Context, db *gorm.DB) {
 	userID, _ := c.Get("userID")
+
+	query, ok := scopeToTerm(c, db)
+	if !ok {
+		return
+	}
+
 	var attendance []models.Attendance
-	if err := db.Preload("Lesson").Where("student_id = ?", uint(userID.(float64))).Find(&attendance).Error; err != nil {
+	if err := query.Preload("Lesson").Preload("Session").Where("student_id = ?", uint(userID.(float64))).Find(&attendance).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance records"})
 		return
 	}
@@ -259,18 +594,44 @@ func GetStudentAttendance(c *gin.Context, db *gorm.DB) {
 
 // GetLessonAttendance godoc
 // @Summary Получить посещаемость занятия
-// @Description Получает все записи о посещаемости для определенного занятия.
+// @Description Получает записи о посещаемости для одной сессии занятия. Сессия выбирается по session_id или дате, по умолчанию - последняя. Каждая запись содержит статус (present, late, absent или excused) и число минут опоздания.
 // @Tags teacher
 // @Produce  json
 // @Security BearerAuth
 // @Param lessonId path int true "ID Занятия"
+// @Param session_id query int false "ID Сессии"
+// @Param date query string false "Дата сессии (YYYY-MM-DD)"
 // @Success 200 {array} models.Attendance "Список записей о посещаемости"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /api/teacher/attendance/{lessonId} [get]
 func GetLessonAttendance(c *gin.Context, db *gorm.DB) {
-	lessonID := c.Param("lessonId")
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonRead(c, db, lessonID) {
+		return
+	}
+
+	session, err := findSession(db, lessonID, c.Query("session_id"), c.Query("date"))
+	if err == errInvalidDate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusOK, []models.Attendance{}) // No session held yet
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance records"})
+		return
+	}
+
 	var attendance []models.Attendance
-	if err := db.Preload("Student").Where("lesson_id = ?", lessonID).Find(&attendance).Error; err != nil {
+	if err := db.Preload("Student").Where("session_id = ?", session.ID).Find(&attendance).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance records"})
 		return
 	}
@@ -299,7 +660,7 @@ func AdminGetUsers(c *gin.Context, db *gorm.DB) {
 
 // AdminCreateUser godoc
 // @Summary Создать пользователя (Админ)
-// @Description Создает нового пользователя с указанными данными.
+// @Description Создает нового пользователя с указанными данными и назначает ему встроенную роль, совпадающую с полем role. Email такого пользователя считается подтвержденным.
 // @Tags admin
 // @Accept  json
 // @Produce  json
@@ -316,14 +677,37 @@ func AdminCreateUser(c *gin.Context, db *gorm.DB) {
 		return
 	}
 
+	// Roles are assigned through /api/admin/users/{id}/roles
+	user.Roles = nil
+	user.Email = strings.TrimSpace(user.Email)
+	if !mailer.ValidAddress(user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), 14)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
 	user.Password = string(hashedPassword)
+	if user.EmailVerifiedAt == nil {
+		// Accounts created by an admin do not need to confirm their email
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
 
-	if err := db.Create(&user).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return rbac.AssignRole(tx, user.ID, user.Role)
+	})
+	if err == rbac.ErrUnknownRole {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role " + user.Role})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
@@ -332,7 +716,7 @@ func AdminCreateUser(c *gin.Context, db *gorm.DB) {
 
 // AdminUpdateUser godoc
 // @Summary Обновить пользователя (Админ)
-// @Description Обновляет данные существующего пользователя.
+// @Description Обновляет данные существующего пользователя. При смене поля role встроенная роль пользователя заменяется новой, остальные роли сохраняются.
 // @Tags admin
 // @Accept  json
 // @Produce  json
@@ -352,12 +736,32 @@ func AdminUpdateUser(c *gin.Context, db *gorm.DB) {
 		return
 	}
 
+	previousRole := user.Role
 	if err := c.ShouldBindJSON(&user); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	user.Roles = nil
+	user.Email = strings.TrimSpace(user.Email)
+	if !mailer.ValidAddress(user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
 
-	if err := db.Save(&user).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if user.Role == previousRole {
+			return nil
+		}
+		return rbac.ReplaceRole(tx, user.ID, previousRole, user.Role)
+	})
+	if err == rbac.ErrUnknownRole {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role " + user.Role})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
@@ -366,7 +770,7 @@ func AdminUpdateUser(c *gin.Context, db *gorm.DB) {
 
 // AdminDeleteUser godoc
 // @Summary Удалить пользователя (Админ)
-// @Description Удаляет пользователя по его ID.
+// @Description Удаляет пользователя по его ID вместе с его объяснительными и приложенными к ним файлами.
 // @Tags admin
 // @Produce  json
 // @Security BearerAuth
@@ -375,12 +779,50 @@ func AdminUpdateUser(c *gin.Context, db *gorm.DB) {
 // @Failure 404 {object} map[string]interface{} "Пользователь не найден"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /api/admin/users/{id} [delete]
-func AdminDeleteUser(c *gin.Context, db *gorm.DB) {
+func AdminDeleteUser(c *gin.Context, db *gorm.DB, store storage.Storage) {
 	id := c.Param("id")
-	if err := db.Delete(&models.User{}, id).Error; err != nil {
+	var fileKeys []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		sessions := tx.Model(&models.AuthSession{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Where("session_id IN (?)", sessions).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&models.AuthSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Group{}).Where("curator_id = ?", id).Update("curator_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("guardian_id = ? OR student_id = ?", id, id).Delete(&models.GuardianLink{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("student_id = ?", id).Delete(&models.GuardianInvite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("student_id = ?", id).Delete(&models.AttendanceChange{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ExcuseRequest{}).Where("student_id = ?", id).Pluck("file_key", &fileKeys).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("student_id = ?", id).Delete(&models.ExcuseRequest{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
+	// Files are only removed once the records are gone for good
+	for _, key := range fileKeys {
+		if err := store.Delete(key); err != nil {
+			log.Printf("storage: failed to delete %s: %v", key, err)
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
@@ -404,43 +846,152 @@ func AdminGetGroups(c *gin.Context, db *gorm.DB) {
 
 // Code Handlers
 
+// maxCodeAttempts bounds how many values GenerateCode draws before giving up
+// on finding one that is not already active.
+const maxCodeAttempts = 10
+
+var (
+	errNoFreeCode  = errors.New("no free attendance code value")
+	errInvalidCode = errors.New("invalid attendance code")
+)
+
+// codeSettings returns the length, alphabet and lifetime of codes for the
+// lesson: its own overrides, falling back to the configured defaults.
+func codeSettings(cfg *config.Config, lesson models.Lesson) (int, string, time.Duration) {
+	length, alphabet, ttl := cfg.CodeLength, cfg.CodeAlphabet, cfg.CodeTTL
+	if lesson.CodeLength != nil {
+		length = *lesson.CodeLength
+	}
+	if lesson.CodeAlphabet != nil {
+		alphabet = *lesson.CodeAlphabet
+	}
+	if lesson.CodeTTLSeconds != nil {
+		ttl = time.Duration(*lesson.CodeTTLSeconds) * time.Second
+	}
+	return length, alphabet, ttl
+}
+
 // GenerateCode godoc
 // @Summary Сгенерировать код посещаемости
-// @Description Генерирует новый 5-значный код для занятия, который истекает через 5 минут.
+// @Description Генерирует новый код для сегодняшней сессии занятия. Длина, алфавит и срок действия кода берутся из настроек занятия или из конфигурации сервера (по умолчанию 5 цифр на 15 минут). Постоянный код не совпадает ни с одним другим активным кодом. Меняющийся код (mode=rotating) пересчитывается каждые 10-30 секунд, его текущее значение возвращает GET на этот же адрес.
 // @Tags teacher
+// @Accept  json
 // @Produce  json
 // @Security BearerAuth
 // @Param lessonId path int true "ID Занятия"
+// @Param request body GenerateCodeRequest true "Параметры кода"
 // @Success 200 {object} models.GeneratedCode "Сгенерированный код"
-// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или занятие не проводится сегодня"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 404 {object} map[string]interface{} "Занятие не найдено"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /api/teacher/lessons/{lessonId}/code [post]
-func GenerateCode(c *gin.Context, db *gorm.DB) {
-	var req struct {
-		LessonID uint `json:"lesson_id" binding:"required"`
-	}
+func GenerateCode(c *gin.Context, db *gorm.DB, cfg *config.Config) {
+	var req GenerateCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Deactivate previous codes for this lesson
-	db.Model(&models.GeneratedCode{}).Where("lesson_id = ?", req.LessonID).Update("is_active", false)
+	mode := req.Mode
+	if mode == "" {
+		mode = cfg.CodeMode
+	}
+	if mode != codes.ModeStatic && mode != codes.ModeRotating {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode, expected static or rotating"})
+		return
+	}
+
+	var lesson models.Lesson
+	if err := db.First(&lesson, req.LessonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+	if !requireLessonAccess(c, db, lesson.ID) {
+		return
+	}
 
-	// Generate new code
-	code := strconv.Itoa(10000 + rand.Intn(90000))
-	newCode := models.GeneratedCode{
-		LessonID:  req.LessonID,
-		Code:      code,
-		ExpiresAt: time.Now().Add(15 * time.Minute),
-		IsActive:  true,
+	now := time.Now()
+	occurs, err := schedule.Occurs(db, lesson, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check lesson schedule"})
+		return
+	}
+	if !occurs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Lesson is not scheduled for today"})
+		return
+	}
+
+	session, err := schedule.EnsureSession(db, lesson, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start lesson session"})
+		return
 	}
 
-	if err := db.Create(&newCode).Error; err != nil {
+	length, alphabet, ttl := codeSettings(cfg, lesson)
+	if mode == codes.ModeRotating {
+		if err := ensureCodeSecret(db, &session); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate code"})
+			return
+		}
+	}
+
+	var newCode models.GeneratedCode
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Deactivate previous codes for this lesson, and expired codes of
+		// every lesson so that their values can be issued again
+		if err := tx.Model(&models.GeneratedCode{}).
+			Where("is_active = ? AND (lesson_id = ? OR expires_at <= ?)", true, lesson.ID, now).
+			Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		// Rotating codes have no stored value to collide
+		if mode == codes.ModeRotating {
+			newCode = models.GeneratedCode{
+				LessonID:    lesson.ID,
+				SessionID:   &session.ID,
+				Mode:        codes.ModeRotating,
+				StepSeconds: int(cfg.CodeRotationStep / time.Second),
+				ExpiresAt:   now.Add(ttl),
+				IsActive:    true,
+			}
+			return tx.Create(&newCode).Error
+		}
+
+		// idx_generated_codes_active_static_code rejects a value that is currently
+		// active for another lesson, so draw again until a free one is found
+		for attempt := 0; attempt < maxCodeAttempts; attempt++ {
+			code, err := codes.Generate(length, alphabet)
+			if err != nil {
+				return err
+			}
+			newCode = models.GeneratedCode{
+				LessonID:  lesson.ID,
+				SessionID: &session.ID,
+				Mode:      codes.ModeStatic,
+				Code:      code,
+				ExpiresAt: now.Add(ttl),
+				IsActive:  true,
+			}
+			result := tx.Clauses(clause.OnConflict{
+				Columns:     []clause.Column{{Name: "code"}},
+				TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "is_active AND mode = 'static'"}}},
+				DoNothing:   true,
+			}).Create(&newCode)
+			if result.Error != nil || result.RowsAffected > 0 {
+				return result.Error
+			}
+		}
+		return errNoFreeCode
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate code"})
 		return
 	}
 
+	newCode.Session = session
+	showCurrentCode(cfg, lesson, &newCode, now)
 	c.JSON(http.StatusOK, newCode)
 }
 
@@ -453,6 +1004,7 @@ func GenerateCode(c *gin.Context, db *gorm.DB) {
 // @Param lessonId path int true "ID Занятия"
 // @Success 200 {object} map[string]interface{} "Код успешно деактивирован"
 // @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
 // @Failure 404 {object} map[string]interface{} "Активный код не найден"
 // @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
 // @Router /api/teacher/lessons/{lessonId}/code [delete]
@@ -464,6 +1016,9 @@ func DeactivateCode(c *gin.Context, db *gorm.DB) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
+	if !requireLessonAccess(c, db, req.LessonID) {
+		return
+	}
 
 	result := db.Model(&models.GeneratedCode{}).
 		Where("lesson_id = ? AND is_active = ?", req.LessonID, true).
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"student-attendance-app/pkg/codes"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testConfig holds the default code settings of LoadConfig.
+var testConfig = &config.Config{
+	CodeLength:       5,
+	CodeAlphabet:     codes.AlphabetDigits,
+	CodeTTL:          15 * time.Minute,
+	CodeMode:         codes.ModeStatic,
+	CodeRotationStep: 15 * time.Second,
+	CodeRotationSkew: 1,
+	LateGracePeriod:  10 * time.Minute,
+}
+
+// newTestDB opens an empty SQLite database with the tables the handlers use.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.Group{}, &models.User{}, &models.GroupInvite{}, &models.GuardianLink{},
+		&models.Term{}, &models.Holiday{},
+		&models.Lesson{}, &models.LessonTeacher{}, &models.LessonDelegation{}, &models.LessonSession{},
+		&models.Attendance{}, &models.AttendanceChange{}, &models.CodeAttempt{}, &models.GeneratedCode{},
+		&models.ExcuseRequest{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+// newTestContext returns a context for a request by userID with body encoded
+// as JSON, and the recorder its response is written to.
+func newTestContext(t *testing.T, userID uint, body interface{}) (*gin.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to encode request: %v", err)
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", float64(userID))
+	return c, w
+}
+
+// create inserts values into db, failing the test on error.
+func create(t *testing.T, db *gorm.DB, values ...interface{}) {
+	t.Helper()
+	for _, value := range values {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", value, err)
+		}
+	}
+}
+
+// attendanceFixture is a lesson of one group with an active code and a
+// student of that group and of another group.
+type attendanceFixture struct {
+	lesson   models.Lesson
+	session  models.LessonSession
+	code     models.GeneratedCode
+	student  models.User
+	outsider models.User
+}
+
+func newAttendanceFixture(t *testing.T, db *gorm.DB) attendanceFixture {
+	t.Helper()
+	var f attendanceFixture
+	groupA := models.Group{Name: "Group A"}
+	groupB := models.Group{Name: "Group B"}
+	create(t, db, &groupA, &groupB)
+
+	f.student = models.User{Identifier: "student001", Password: "x", Name: "Student", Email: "student001@example.com", Role: "student", GroupID: &groupA.ID}
+	f.outsider = models.User{Identifier: "student002", Password: "x", Name: "Outsider", Email: "student002@example.com", Role: "student", GroupID: &groupB.ID}
+	f.lesson = models.Lesson{Name: "Math", Day: "Понедельник", Time: "09:00-10:30", GroupID: &groupA.ID}
+	create(t, db, &f.student, &f.outsider, &f.lesson)
+
+	now := time.Now()
+	f.session = models.LessonSession{LessonID: f.lesson.ID, Date: now.Truncate(24 * time.Hour), StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	create(t, db, &f.session)
+	f.code = models.GeneratedCode{LessonID: f.lesson.ID, SessionID: &f.session.ID, Code: "12345", ExpiresAt: now.Add(time.Minute), IsActive: true}
+	create(t, db, &f.code)
+	return f
+}
+
+func TestSubmitAttendanceRequiresLessonGroup(t *testing.T) {
+	db := newTestDB(t)
+	f := newAttendanceFixture(t, db)
+
+	c, w := newTestContext(t, f.outsider.ID, SubmitAttendanceRequest{LessonID: f.lesson.ID, Code: f.code.Code})
+	SubmitAttendance(c, db, testConfig)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("outsider got status %d, want %d: %s", w.Code, http.StatusForbidden, w.Body)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp["code"] != errCodeNotInLessonGroup {
+		t.Errorf("outsider got %s, want code %q", w.Body, errCodeNotInLessonGroup)
+	}
+
+	c, w = newTestContext(t, f.student.ID, SubmitAttendanceRequest{LessonID: f.lesson.ID, Code: f.code.Code})
+	SubmitAttendance(c, db, testConfig)
+	if w.Code != http.StatusOK {
+		t.Fatalf("student got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var records []models.Attendance
+	db.Find(&records)
+	if len(records) != 1 || records[0].StudentID != f.student.ID {
+		t.Errorf("attendance records = %+v, want one for student %d", records, f.student.ID)
+	}
+}
+
+func TestSubmitAttendanceIsIdempotentPerSession(t *testing.T) {
+	db := newTestDB(t)
+	f := newAttendanceFixture(t, db)
+
+	submit := func() map[string]interface{} {
+		t.Helper()
+		c, w := newTestContext(t, f.student.ID, SubmitAttendanceRequest{LessonID: f.lesson.ID, Code: f.code.Code})
+		SubmitAttendance(c, db, testConfig)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+		}
+		var resp map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	first := submit()
+	if first["already_marked"] != false {
+		t.Errorf("first submission already_marked = %v, want false", first["already_marked"])
+	}
+	second := submit()
+	if second["already_marked"] != true {
+		t.Errorf("second submission already_marked = %v, want true", second["already_marked"])
+	}
+	if second["submitted_at"] != first["submitted_at"] {
+		t.Errorf("second submission submitted_at = %v, want the first one %v", second["submitted_at"], first["submitted_at"])
+	}
+
+	var count int64
+	db.Model(&models.Attendance{}).Where("session_id = ? AND student_id = ?", f.session.ID, f.student.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("%d attendance records for the session, want 1", count)
+	}
+
+	// A later session of the same lesson is a separate mark
+	next := models.LessonSession{LessonID: f.lesson.ID, Date: f.session.Date.AddDate(0, 0, 7), StartsAt: f.session.StartsAt.AddDate(0, 0, 7), EndsAt: f.session.EndsAt.AddDate(0, 0, 7)}
+	create(t, db, &next)
+	db.Model(&f.code).Update("session_id", next.ID)
+	if resp := submit(); resp["already_marked"] != false {
+		t.Errorf("submission for the next session already_marked = %v, want false", resp["already_marked"])
+	}
+}
+
+func TestSubmitAttendanceClassifiesLateness(t *testing.T) {
+	tests := []struct {
+		name        string
+		startedAgo  time.Duration
+		status      string
+		minutesLate float64
+	}{
+		{"within the grace period", 5 * time.Minute, models.AttendancePresent, 0},
+		{"after the grace period", 25 * time.Minute, models.AttendanceLate, 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			f := newAttendanceFixture(t, db)
+			db.Model(&f.session).Update("starts_at", time.Now().Add(-tt.startedAgo))
+
+			c, w := newTestContext(t, f.student.ID, SubmitAttendanceRequest{LessonID: f.lesson.ID, Code: f.code.Code})
+			SubmitAttendance(c, db, testConfig)
+			var resp map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp["status"] != tt.status || resp["minutes_late"] != tt.minutesLate {
+				t.Errorf("got status %v, %v minutes late, want %s, %v", resp["status"], resp["minutes_late"], tt.status, tt.minutesLate)
+			}
+
+			var record models.Attendance
+			db.Where("session_id = ? AND student_id = ?", f.session.ID, f.student.ID).First(&record)
+			if record.Status != tt.status {
+				t.Errorf("stored status %q, want %q", record.Status, tt.status)
+			}
+		})
+	}
+}
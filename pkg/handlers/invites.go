@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/codes"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// inviteCodeLength is long enough that invite codes cannot be guessed.
+const inviteCodeLength = 10
+
+var errInviteUsedUp = errors.New("invite code used up")
+
+type InviteRequest struct {
+	MaxUses        *int `json:"max_uses" example:"30"`         // Unlimited when omitted
+	ExpiresInHours *int `json:"expires_in_hours" example:"72"` // Never expires when omitted
+}
+
+// normalizeInviteCode accepts codes typed in any case and with stray spaces.
+func normalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// inviteUsable reports whether the invite can still be used at now.
+func inviteUsable(invite models.GroupInvite, now time.Time) bool {
+	if invite.RevokedAt != nil {
+		return false
+	}
+	if invite.ExpiresAt != nil && !invite.ExpiresAt.After(now) {
+		return false
+	}
+	return invite.MaxUses == nil || invite.Uses < *invite.MaxUses
+}
+
+// useInvite counts one registration against the invite. It fails with
+// errInviteUsedUp when a concurrent registration took the last use.
+func useInvite(tx *gorm.DB, inviteID uint) error {
+	result := tx.Model(&models.GroupInvite{}).
+		Where("id = ? AND revoked_at IS NULL AND (max_uses IS NULL OR uses < max_uses)", inviteID).
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInviteUsedUp
+	}
+	return nil
+}
+
+// GetRegistrationPolicy godoc
+// @Summary Получить политику регистрации
+// @Description Возвращает политику самостоятельной регистрации студентов: open (группа выбирается из списка), invite (нужен код приглашения) или approval (аккаунт одобряет администратор).
+// @Tags public
+// @Produce  json
+// @Success 200 {object} map[string]interface{} "Политика регистрации (policy)"
+// @Router /auth/registration-policy [get]
+func GetRegistrationPolicy(c *gin.Context, cfg *config.Config) {
+	c.JSON(http.StatusOK, gin.H{"policy": cfg.RegistrationPolicy})
+}
+
+// AdminGetInvites godoc
+// @Summary Получить коды приглашения (Админ)
+// @Description Возвращает коды приглашения, опционально только для одной группы.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param group_id query int false "ID Группы"
+// @Success 200 {array} models.GroupInvite "Список кодов приглашения"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/invites [get]
+func AdminGetInvites(c *gin.Context, db *gorm.DB) {
+	query := db.Preload("Group").Order("created_at desc")
+	if groupID := c.Query("group_id"); groupID != "" {
+		query = query.Where("group_id = ?", groupID)
+	}
+
+	var invites []models.GroupInvite
+	if err := query.Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invites"})
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// AdminCreateInvite godoc
+// @Summary Создать код приглашения в группу (Админ)
+// @Description Создает код приглашения, по которому студенты регистрируются сразу в эту группу. Количество использований и срок действия можно ограничить.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Param invite body InviteRequest true "Ограничения кода"
+// @Success 200 {object} models.GroupInvite "Созданный код приглашения"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/groups/{id}/invites [post]
+func AdminCreateInvite(c *gin.Context, db *gorm.DB) {
+	req, ok := bindInviteRequest(c)
+	if !ok {
+		return
+	}
+
+	var group models.Group
+	if err := db.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	createInvite(c, db, group, req)
+}
+
+// bindInviteRequest reads the limits of a new invite code. It writes a 400
+// response and returns false when they are invalid.
+func bindInviteRequest(c *gin.Context) (InviteRequest, bool) {
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, false
+	}
+	if req.MaxUses != nil && *req.MaxUses < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_uses must be at least 1"})
+		return req, false
+	}
+	if req.ExpiresInHours != nil && *req.ExpiresInHours < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_hours must be at least 1"})
+		return req, false
+	}
+	return req, true
+}
+
+// createInvite generates a unique invite code to the group and sends it.
+func createInvite(c *gin.Context, db *gorm.DB, group models.Group, req InviteRequest) {
+	invite := models.GroupInvite{
+		GroupID:     group.ID,
+		CreatedByID: currentUserID(c),
+		MaxUses:     req.MaxUses,
+	}
+	if req.ExpiresInHours != nil {
+		expiresAt := time.Now().Add(time.Duration(*req.ExpiresInHours) * time.Hour)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	var err error
+	for attempt := 0; attempt < maxCodeAttempts; attempt++ {
+		invite.ID = 0
+		if invite.Code, err = codes.Generate(inviteCodeLength, codes.AlphabetAlphanumeric); err != nil {
+			break
+		}
+		if err = db.Create(&invite).Error; !isDuplicateKeyError(err) {
+			break
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+
+	invite.Group = group
+	c.JSON(http.StatusOK, invite)
+}
+
+// AdminRevokeInvite godoc
+// @Summary Отозвать код приглашения (Админ)
+// @Description Отзывает код приглашения. Уже зарегистрированные по нему студенты остаются в группе.
+// @Tags admin
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Кода приглашения"
+// @Success 200 {object} map[string]interface{} "Код отозван"
+// @Failure 404 {object} map[string]interface{} "Код приглашения не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/admin/invites/{id} [delete]
+func AdminRevokeInvite(c *gin.Context, db *gorm.DB) {
+	revokeInvite(c, db, db)
+}
+
+// revokeInvite revokes the invite from the id path parameter. The invite is
+// looked up with the lookup query, so out of scope invites are not found.
+func revokeInvite(c *gin.Context, db *gorm.DB, lookup *gorm.DB) {
+	var invite models.GroupInvite
+	if err := lookup.First(&invite, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+		return
+	}
+	if invite.RevokedAt == nil {
+		if err := db.Model(&invite).Update("revoked_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invite"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked successfully"})
+}
+
+// GetCuratedInvites godoc
+// @Summary Получить коды приглашения в курируемую группу
+// @Description Возвращает коды приглашения в группу, которую курирует пользователь.
+// @Tags curator
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Success 200 {array} models.GroupInvite "Список кодов приглашения"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/groups/{id}/invites [get]
+func GetCuratedInvites(c *gin.Context, db *gorm.DB) {
+	group, ok := findCuratedGroup(c, db)
+	if !ok {
+		return
+	}
+
+	var invites []models.GroupInvite
+	if err := db.Preload("Group").Where("group_id = ?", group.ID).Order("created_at desc").Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve invites"})
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// CreateCuratedInvite godoc
+// @Summary Создать код приглашения в курируемую группу
+// @Description Создает код приглашения в группу, которую курирует пользователь. Студенты регистрируются по нему сразу в эту группу. Количество использований и срок действия можно ограничить.
+// @Tags curator
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Группы"
+// @Param invite body InviteRequest true "Ограничения кода"
+// @Success 200 {object} models.GroupInvite "Созданный код приглашения"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 404 {object} map[string]interface{} "Группа не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/groups/{id}/invites [post]
+func CreateCuratedInvite(c *gin.Context, db *gorm.DB) {
+	group, ok := findCuratedGroup(c, db)
+	if !ok {
+		return
+	}
+	req, ok := bindInviteRequest(c)
+	if !ok {
+		return
+	}
+	createInvite(c, db, group, req)
+}
+
+// RevokeCuratedInvite godoc
+// @Summary Отозвать код приглашения в курируемую группу
+// @Description Отзывает код приглашения в группу, которую курирует пользователь. Уже зарегистрированные по нему студенты остаются в группе.
+// @Tags curator
+// @Produce  json
+// @Security BearerAuth
+// @Param id path int true "ID Кода приглашения"
+// @Success 200 {object} map[string]interface{} "Код отозван"
+// @Failure 404 {object} map[string]interface{} "Код приглашения не найден"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/curator/invites/{id} [delete]
+func RevokeCuratedInvite(c *gin.Context, db *gorm.DB) {
+	revokeInvite(c, db, db.Where("group_id IN (?)", curatedGroupIDs(c, db)))
+}
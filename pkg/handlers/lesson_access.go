@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DelegationRequest struct {
+	TeacherID uint       `json:"teacher_id" binding:"required" example:"4"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2025-10-01T00:00:00Z"`
+}
+
+// managedLessons scopes a lesson query to the lessons the user is assigned to
+// or holds an unexpired delegation for.
+func managedLessons(db *gorm.DB, userID uint) *gorm.DB {
+	tx := db.Session(&gorm.Session{NewDB: true})
+	assigned := tx.Model(&models.LessonTeacher{}).Select("lesson_id").Where("teacher_id = ?", userID)
+	delegated := tx.Model(&models.LessonDelegation{}).Select("lesson_id").
+		Where("grantee_id = ? AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now())
+	return db.Where("lessons.id IN (?) OR lessons.id IN (?)", assigned, delegated)
+}
+
+// isLessonLead reports whether the user is a lead teacher of the lesson.
+func isLessonLead(db *gorm.DB, lessonID, userID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.LessonTeacher{}).
+		Where("lesson_id = ? AND teacher_id = ? AND role = ?", lessonID, userID, models.LessonTeacherLead).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// requireLessonAccess checks that the current user may open codes and read
+// attendance of the lesson. It writes a 403 or 500 response and returns false
+// otherwise. Holders of lessons.manage may act on every lesson.
+func requireLessonAccess(c *gin.Context, db *gorm.DB, lessonID uint) bool {
+	return requireManagedLesson(c, db, lessonID, rbac.LessonsManage)
+}
+
+// requireLessonRead is requireLessonAccess for handlers that only read
+// attendance, which holders of attendance.read.all may do for every lesson.
+func requireLessonRead(c *gin.Context, db *gorm.DB, lessonID uint) bool {
+	return requireManagedLesson(c, db, lessonID, rbac.LessonsManage, rbac.AttendanceReadAll)
+}
+
+// requireManagedLesson checks that the lesson is managed by the current user
+// unless they hold any of the bypass permissions.
+func requireManagedLesson(c *gin.Context, db *gorm.DB, lessonID uint, bypass ...string) bool {
+	if rbac.Has(c, bypass...) {
+		return true
+	}
+	var count int64
+	err := managedLessons(db, currentUserID(c)).Model(&models.Lesson{}).
+		Where("lessons.id = ?", lessonID).
+		Count(&count).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check lesson access"})
+		return false
+	}
+	if count == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not assigned to this lesson"})
+		return false
+	}
+	return true
+}
+
+// requireLessonLead checks that the current user is a lead teacher of the
+// lesson or holds lessons.manage. It writes a 403 or 500 response and returns
+// false otherwise.
+func requireLessonLead(c *gin.Context, db *gorm.DB, lessonID uint) bool {
+	if rbac.Has(c, rbac.LessonsManage) {
+		return true
+	}
+	lead, err := isLessonLead(db, lessonID, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check lesson access"})
+		return false
+	}
+	if !lead {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the lesson's lead teacher can manage delegations"})
+		return false
+	}
+	return true
+}
+
+// GetLessonDelegations godoc
+// @Summary Получить делегирования занятия
+// @Description Возвращает список преподавателей, которым переданы права на занятие.
+// @Tags teacher
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Success 200 {array} models.LessonDelegation "Список делегирований"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/delegations [get]
+func GetLessonDelegations(c *gin.Context, db *gorm.DB) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonAccess(c, db, lessonID) {
+		return
+	}
+
+	var delegations []models.LessonDelegation
+	if err := db.Preload("Grantee").Where("lesson_id = ?", lessonID).Order("created_at desc").Find(&delegations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve delegations"})
+		return
+	}
+	c.JSON(http.StatusOK, delegations)
+}
+
+// CreateLessonDelegation godoc
+// @Summary Делегировать права на занятие
+// @Description Ведущий преподаватель передает другому преподавателю (ассистенту или замещающему) право генерировать коды и просматривать посещаемость занятия, опционально до указанного времени.
+// @Tags teacher
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param delegation body DelegationRequest true "Данные делегирования"
+// @Success 200 {object} models.LessonDelegation "Созданное делегирование"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Пользователь не является ведущим преподавателем занятия"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/delegations [post]
+func CreateLessonDelegation(c *gin.Context, db *gorm.DB) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonLead(c, db, lessonID) {
+		return
+	}
+
+	var req DelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	var grantee models.User
+	if err := db.Where("id IN (?)", rbac.UsersWith(db, rbac.LessonsTeach)).First(&grantee, req.TeacherID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher ID"})
+		return
+	}
+
+	delegation := models.LessonDelegation{
+		LessonID:    lessonID,
+		GranteeID:   grantee.ID,
+		GrantedByID: currentUserID(c),
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := db.Create(&delegation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create delegation"})
+		return
+	}
+	delegation.Grantee = grantee
+	c.JSON(http.StatusOK, delegation)
+}
+
+// DeleteLessonDelegation godoc
+// @Summary Отозвать делегирование
+// @Description Ведущий преподаватель отзывает ранее переданные права на занятие.
+// @Tags teacher
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param id path int true "ID Делегирования"
+// @Success 200 {object} map[string]interface{} "Делегирование отозвано"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Пользователь не является ведущим преподавателем занятия"
+// @Failure 404 {object} map[string]interface{} "Делегирование не найдено"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/delegations/{id} [delete]
+func DeleteLessonDelegation(c *gin.Context, db *gorm.DB) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonLead(c, db, lessonID) {
+		return
+	}
+
+	result := db.Where("lesson_id = ?", lessonID).Delete(&models.LessonDelegation{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke delegation"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delegation not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Delegation revoked successfully"})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetLessonAttendanceScope(t *testing.T) {
+	db := newTestDB(t)
+	teacher := models.User{Identifier: "teacher001", Password: "x", Name: "Teacher", Email: "teacher001@example.com", Role: "teacher"}
+	own := models.Lesson{Name: "Math", Day: "Понедельник", Time: "09:00-10:30"}
+	other := models.Lesson{Name: "Physics", Day: "Понедельник", Time: "10:40-12:10"}
+	create(t, db, &teacher, &own, &other)
+	create(t, db, &models.LessonTeacher{LessonID: own.ID, TeacherID: teacher.ID, Role: models.LessonTeacherLead})
+
+	delegated := models.Lesson{Name: "Chemistry", Day: "Вторник", Time: "09:00-10:30"}
+	expired := models.Lesson{Name: "Biology", Day: "Вторник", Time: "10:40-12:10"}
+	create(t, db, &delegated, &expired)
+	past := time.Now().Add(-time.Hour)
+	create(t, db,
+		&models.LessonDelegation{LessonID: delegated.ID, GranteeID: teacher.ID, GrantedByID: teacher.ID},
+		&models.LessonDelegation{LessonID: expired.ID, GranteeID: teacher.ID, GrantedByID: teacher.ID, ExpiresAt: &past},
+	)
+
+	tests := []struct {
+		name        string
+		lesson      models.Lesson
+		permissions map[string]bool
+		want        int
+	}{
+		{"assigned lesson", own, map[string]bool{rbac.AttendanceReadLesson: true}, http.StatusOK},
+		{"delegated lesson", delegated, map[string]bool{rbac.AttendanceReadLesson: true}, http.StatusOK},
+		{"expired delegation", expired, map[string]bool{rbac.AttendanceReadLesson: true}, http.StatusForbidden},
+		{"lesson of another teacher", other, map[string]bool{rbac.AttendanceReadLesson: true}, http.StatusForbidden},
+		{"lesson of another teacher with attendance.read.all", other, map[string]bool{rbac.AttendanceReadAll: true}, http.StatusOK},
+		{"lesson of another teacher with lessons.manage", other, map[string]bool{rbac.LessonsManage: true}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext(t, teacher.ID, nil)
+			c.Params = gin.Params{{Key: "lessonId", Value: strconv.Itoa(int(tt.lesson.ID))}}
+			rbac.SetPermissions(c, tt.permissions)
+			GetLessonAttendance(c, db)
+			if w.Code != tt.want {
+				t.Errorf("got status %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
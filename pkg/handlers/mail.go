@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+	"net/url"
+	"strings"
+	"student-attendance-app/pkg/auth"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/mailer"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// A new mailed token is issued at most once per userTokenInterval and user,
+// so that the public endpoints cannot be used to flood a mailbox.
+const userTokenInterval = time.Minute
+
+// sendMail delivers msg in the background, so that the response time does not
+// depend on whether a message was sent at all.
+func sendMail(m mailer.Mailer, msg mailer.Message) {
+	go func() {
+		if err := m.Send(msg); err != nil {
+			log.Printf("mailer: failed to send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+// frontendLink builds a link to a frontend page carrying a token.
+func frontendLink(cfg *config.Config, path, token string) string {
+	return strings.TrimRight(cfg.PublicURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// mailUserToken issues a token for the user and mails the message compose
+// builds around a link to the frontend page at path. It does nothing if the
+// user was mailed a token for the same purpose within userTokenInterval.
+func mailUserToken(db *gorm.DB, cfg *config.Config, m mailer.Mailer, user models.User, purpose, path string, ttl time.Duration, compose func(link string) mailer.Message) error {
+	var recent int64
+	if err := db.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL AND created_at > ?", user.ID, purpose, time.Now().Add(-userTokenInterval)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token, err := auth.IssueUserToken(db, user.ID, purpose, ttl)
+	if err != nil {
+		return err
+	}
+	sendMail(m, compose(frontendLink(cfg, path, token)))
+	return nil
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ManualAttendanceRequest struct {
+	Status      string `json:"status" binding:"required" example:"present"` // 'present', 'late' or 'excused'
+	MinutesLate int    `json:"minutes_late" example:"0"`                    // Only for late marks
+	Reason      string `json:"reason" binding:"required" example:"Разрядился телефон"`
+}
+
+type AttendanceReasonRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Отмечен по ошибке"`
+}
+
+var errNoAttendanceMark = errors.New("student has no mark for this session")
+
+// manualStatuses are the statuses a teacher may set. Absent students have no
+// mark, so a teacher records an absence by removing the mark.
+var manualStatuses = map[string]bool{
+	models.AttendancePresent: true,
+	models.AttendanceLate:    true,
+	models.AttendanceExcused: true,
+}
+
+// findMarkTarget resolves the lesson, session and student of a manual mark
+// from the path and the session_id or date query values. It writes an error
+// response and returns false when the current user may not mark the lesson,
+// the session has not started yet or the student does not belong to it.
+func findMarkTarget(c *gin.Context, db *gorm.DB) (models.Lesson, models.LessonSession, models.User, bool) {
+	var lesson models.Lesson
+	var session models.LessonSession
+	var student models.User
+
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return lesson, session, student, false
+	}
+	studentID, ok := paramUint(c, "studentId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return lesson, session, student, false
+	}
+	if !requireLessonAccess(c, db, lessonID) {
+		return lesson, session, student, false
+	}
+
+	if err := db.First(&lesson, lessonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return lesson, session, student, false
+	}
+	session, err := findSession(db, lessonID, c.Query("session_id"), c.Query("date"))
+	if err == errInvalidDate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return lesson, session, student, false
+	}
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return lesson, session, student, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve session"})
+		return lesson, session, student, false
+	}
+	if session.StartsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has not started yet"})
+		return lesson, session, student, false
+	}
+
+	if err := db.First(&student, "id = ? AND role = ?", studentID, "student").Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return lesson, session, student, false
+	}
+	// Students who have left the group keep the marks they already have
+	if lesson.GroupID == nil || student.GroupID == nil || *lesson.GroupID != *student.GroupID {
+		var marks int64
+		if err := db.Model(&models.Attendance{}).Where("session_id = ? AND student_id = ?", session.ID, student.ID).Count(&marks).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance records"})
+			return lesson, session, student, false
+		}
+		if marks == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Student is not a member of this lesson's group", "code": errCodeNotInLessonGroup})
+			return lesson, session, student, false
+		}
+	}
+	return lesson, session, student, true
+}
+
+// recordAttendanceChange saves the audit entry of a manual change.
+func recordAttendanceChange(tx *gorm.DB, c *gin.Context, session models.LessonSession, studentID uint, oldStatus, newStatus, reason string, at time.Time) error {
+	changedByID := currentUserID(c)
+	return tx.Create(&models.AttendanceChange{
+		LessonID:    session.LessonID,
+		SessionID:   session.ID,
+		StudentID:   studentID,
+		OldStatus:   oldStatus,
+		NewStatus:   newStatus,
+		Reason:      reason,
+		ChangedByID: &changedByID,
+		ChangedAt:   at,
+	}).Error
+}
+
+// SetStudentAttendance godoc
+// @Summary Отметить студента вручную
+// @Description Преподаватель отмечает студента на сессии занятия или меняет статус существующей отметки (present, late или excused), например если у студента разрядился телефон. Причина обязательна; изменение записывается в журнал изменений вместе с автором и временем. Такие отметки получают source = manual. Сессия выбирается по session_id или дате, по умолчанию - последняя.
+// @Tags teacher
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param studentId path int true "ID Студента"
+// @Param session_id query int false "ID Сессии"
+// @Param date query string false "Дата сессии (YYYY-MM-DD)"
+// @Param mark body ManualAttendanceRequest true "Статус и причина"
+// @Success 200 {object} models.Attendance "Сохраненная отметка"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос, сессия еще не началась или студент не из группы занятия"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 404 {object} map[string]interface{} "Занятие, сессия или студент не найдены"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/attendance/{studentId} [put]
+func SetStudentAttendance(c *gin.Context, db *gorm.DB) {
+	var req ManualAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+	if !manualStatuses[req.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status, expected present, late or excused"})
+		return
+	}
+	if req.MinutesLate < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "minutes_late must not be negative"})
+		return
+	}
+	if req.Status != models.AttendanceLate {
+		req.MinutesLate = 0
+	}
+
+	lesson, session, student, ok := findMarkTarget(c, db)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	markedByID := currentUserID(c)
+	var attendance models.Attendance
+	err := db.Transaction(func(tx *gorm.DB) error {
+		oldStatus := ""
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("session_id = ? AND student_id = ?", session.ID, student.ID).
+			First(&attendance).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			attendance = models.Attendance{
+				LessonID:    lesson.ID,
+				SessionID:   &session.ID,
+				StudentID:   student.ID,
+				SubmittedAt: now,
+			}
+		case err != nil:
+			return err
+		default:
+			oldStatus = attendance.Status
+		}
+
+		attendance.Status = req.Status
+		attendance.MinutesLate = req.MinutesLate
+		attendance.Source = models.AttendanceSourceManual
+		attendance.MarkedByID = &markedByID
+		if err := tx.Save(&attendance).Error; err != nil {
+			return err
+		}
+		return recordAttendanceChange(tx, c, session, student.ID, oldStatus, req.Status, req.Reason, now)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attendance"})
+		return
+	}
+
+	if err := db.Preload("Student").Preload("MarkedBy").First(&attendance, attendance.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance records"})
+		return
+	}
+	c.JSON(http.StatusOK, attendance)
+}
+
+// RemoveStudentAttendance godoc
+// @Summary Снять отметку студента
+// @Description Преподаватель удаляет отметку студента на сессии занятия, после чего студент считается отсутствующим. Причина обязательна и записывается в журнал изменений. Сессия выбирается по session_id или дате, по умолчанию - последняя.
+// @Tags teacher
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param studentId path int true "ID Студента"
+// @Param session_id query int false "ID Сессии"
+// @Param date query string false "Дата сессии (YYYY-MM-DD)"
+// @Param reason body AttendanceReasonRequest true "Причина"
+// @Success 200 {object} map[string]interface{} "Отметка снята"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос или сессия еще не началась"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 404 {object} map[string]interface{} "Занятие, сессия, студент или отметка не найдены"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/attendance/{studentId} [delete]
+func RemoveStudentAttendance(c *gin.Context, db *gorm.DB) {
+	var req AttendanceReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	_, session, student, ok := findMarkTarget(c, db)
+	if !ok {
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var attendance models.Attendance
+		if err := tx.Clauses(clause.Returning{}).
+			Where("session_id = ? AND student_id = ?", session.ID, student.ID).
+			Delete(&attendance).Error; err != nil {
+			return err
+		}
+		if attendance.ID == 0 {
+			return errNoAttendanceMark
+		}
+		return recordAttendanceChange(tx, c, session, student.ID, attendance.Status, "", req.Reason, time.Now())
+	})
+	if err == errNoAttendanceMark {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student has no mark for this session"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove attendance"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attendance mark removed successfully"})
+}
+
+// GetAttendanceChanges godoc
+// @Summary Получить журнал изменений посещаемости
+// @Description Возвращает ручные отметки, исправления и снятия отметок на занятии с причиной, автором и временем, начиная с последних. С session_id или date - только для одной сессии.
+// @Tags teacher
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param session_id query int false "ID Сессии"
+// @Param date query string false "Дата сессии (YYYY-MM-DD)"
+// @Success 200 {array} models.AttendanceChange "Журнал изменений"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 404 {object} map[string]interface{} "Сессия не найдена"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/attendance/changes [get]
+func GetAttendanceChanges(c *gin.Context, db *gorm.DB) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonRead(c, db, lessonID) {
+		return
+	}
+
+	query := db.Where("lesson_id = ?", lessonID)
+	if c.Query("session_id") != "" || c.Query("date") != "" {
+		session, err := findSession(db, lessonID, c.Query("session_id"), c.Query("date"))
+		if err == errInvalidDate {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve session"})
+			return
+		}
+		query = query.Where("session_id = ?", session.ID)
+	}
+
+	var changes []models.AttendanceChange
+	if err := query.Preload("Student").Preload("ChangedBy").Order("changed_at desc").Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance changes"})
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/rbac"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestManualAttendanceAuditTrail(t *testing.T) {
+	db := newTestDB(t)
+	f := newAttendanceFixture(t, db)
+	teacher := models.User{Identifier: "teacher001", Password: "x", Name: "Teacher", Email: "teacher001@example.com", Role: "teacher"}
+	stranger := models.User{Identifier: "teacher002", Password: "x", Name: "Stranger", Email: "teacher002@example.com", Role: "teacher"}
+	create(t, db, &teacher, &stranger)
+	create(t, db, &models.LessonTeacher{LessonID: f.lesson.ID, TeacherID: teacher.ID, Role: models.LessonTeacherLead})
+
+	mark := func(userID, studentID uint, body interface{}, handler func(c *gin.Context, db *gorm.DB)) (int, []byte) {
+		t.Helper()
+		c, w := newTestContext(t, userID, body)
+		c.Params = gin.Params{
+			{Key: "lessonId", Value: strconv.Itoa(int(f.lesson.ID))},
+			{Key: "studentId", Value: strconv.Itoa(int(studentID))},
+		}
+		rbac.SetPermissions(c, map[string]bool{rbac.CodesGenerate: true, rbac.AttendanceReadLesson: true})
+		handler(c, db)
+		return w.Code, w.Body.Bytes()
+	}
+
+	// Rejected requests leave no mark and no audit entry
+	rejected := []struct {
+		name    string
+		userID  uint
+		student uint
+		body    interface{}
+		want    int
+	}{
+		{"blank reason", teacher.ID, f.student.ID, ManualAttendanceRequest{Status: models.AttendancePresent, Reason: "  "}, http.StatusBadRequest},
+		{"absent is not a status", teacher.ID, f.student.ID, ManualAttendanceRequest{Status: models.AttendanceAbsent, Reason: "Болел"}, http.StatusBadRequest},
+		{"student of another group", teacher.ID, f.outsider.ID, ManualAttendanceRequest{Status: models.AttendancePresent, Reason: "Разрядился телефон"}, http.StatusBadRequest},
+		{"teacher of another lesson", stranger.ID, f.student.ID, ManualAttendanceRequest{Status: models.AttendancePresent, Reason: "Разрядился телефон"}, http.StatusForbidden},
+	}
+	for _, tt := range rejected {
+		if status, body := mark(tt.userID, tt.student, tt.body, SetStudentAttendance); status != tt.want {
+			t.Errorf("%s: got status %d, want %d: %s", tt.name, status, tt.want, body)
+		}
+	}
+
+	status, body := mark(teacher.ID, f.student.ID, ManualAttendanceRequest{Status: models.AttendancePresent, Reason: "Разрядился телефон"}, SetStudentAttendance)
+	if status != http.StatusOK {
+		t.Fatalf("manual mark got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	var attendance models.Attendance
+	if err := json.Unmarshal(body, &attendance); err != nil {
+		t.Fatalf("failed to decode attendance: %v", err)
+	}
+	if attendance.Source != models.AttendanceSourceManual || attendance.MarkedByID == nil || *attendance.MarkedByID != teacher.ID {
+		t.Errorf("mark source %q by %v, want manual by %d", attendance.Source, attendance.MarkedByID, teacher.ID)
+	}
+
+	status, body = mark(teacher.ID, f.student.ID, ManualAttendanceRequest{Status: models.AttendanceLate, MinutesLate: 15, Reason: "Пришел к середине пары"}, SetStudentAttendance)
+	if status != http.StatusOK {
+		t.Fatalf("status change got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+
+	status, body = mark(teacher.ID, f.student.ID, AttendanceReasonRequest{Reason: "Отмечен по ошибке"}, RemoveStudentAttendance)
+	if status != http.StatusOK {
+		t.Fatalf("removing the mark got status %d, want %d: %s", status, http.StatusOK, body)
+	}
+	var marks int64
+	db.Model(&models.Attendance{}).Where("session_id = ? AND student_id = ?", f.session.ID, f.student.ID).Count(&marks)
+	if marks != 0 {
+		t.Errorf("%d marks left after removing the mark", marks)
+	}
+	if status, _ := mark(teacher.ID, f.student.ID, AttendanceReasonRequest{Reason: "Отмечен по ошибке"}, RemoveStudentAttendance); status != http.StatusNotFound {
+		t.Errorf("removing a missing mark got status %d, want %d", status, http.StatusNotFound)
+	}
+
+	var changes []models.AttendanceChange
+	db.Order("id").Find(&changes)
+	want := []struct{ oldStatus, newStatus, reason string }{
+		{"", models.AttendancePresent, "Разрядился телефон"},
+		{models.AttendancePresent, models.AttendanceLate, "Пришел к середине пары"},
+		{models.AttendanceLate, "", "Отмечен по ошибке"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("%d audit entries, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, change := range changes {
+		if change.OldStatus != want[i].oldStatus || change.NewStatus != want[i].newStatus || change.Reason != want[i].reason {
+			t.Errorf("audit entry %d = %q -> %q (%s), want %q -> %q (%s)", i,
+				change.OldStatus, change.NewStatus, change.Reason, want[i].oldStatus, want[i].newStatus, want[i].reason)
+		}
+		if change.SessionID != f.session.ID || change.StudentID != f.student.ID || change.ChangedByID == nil || *change.ChangedByID != teacher.ID {
+			t.Errorf("audit entry %d = %+v, want session %d, student %d, changed by %d", i, change, f.session.ID, f.student.ID, teacher.ID)
+		}
+	}
+}
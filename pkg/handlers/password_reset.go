@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/auth"
+	"student-attendance-app/pkg/config"
+	"student-attendance-app/pkg/mailer"
+	"student-attendance-app/pkg/models"
+	"student-attendance-app/pkg/throttle"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Password reset links are valid for an hour.
+const passwordResetTTL = time.Hour
+
+type ForgotPasswordRequest struct {
+	Login string `json:"login" binding:"required" example:"student001"` // Identifier or email
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required" example:"mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"`
+	Password string `json:"password" binding:"required" example:"newsecurepassword"`
+}
+
+// ForgotPassword godoc
+// @Summary Запросить сброс пароля
+// @Description Отправляет на email пользователя одноразовую ссылку для сброса пароля, действующую 1 час. Ответ одинаков для существующих и несуществующих пользователей.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   request body ForgotPasswordRequest true "Логин или email"
+// @Success 200 {object} map[string]interface{} "Запрос принят"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Router /auth/forgot-password [post]
+func ForgotPassword(c *gin.Context, db *gorm.DB, cfg *config.Config, m mailer.Mailer) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Whatever happens below, the client gets the same answer, so that the
+	// endpoint cannot be used to find out which accounts exist
+	defer c.JSON(http.StatusOK, gin.H{"message": "If the account exists, a password reset link has been sent to its email"})
+
+	login := strings.TrimSpace(req.Login)
+	var user models.User
+	if err := db.Where("identifier = ? OR LOWER(email) = LOWER(?)", login, login).First(&user).Error; err != nil {
+		return
+	}
+
+	err := mailUserToken(db, cfg, m, user, models.TokenPasswordReset, "/reset-password", passwordResetTTL, func(link string) mailer.Message {
+		return mailer.Message{
+			To:      user.Email,
+			Subject: "Сброс пароля",
+			Body: fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы задать новый пароль, откройте ссылку:\n%s\n\nСсылка действует 1 час. Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.\n",
+				user.Name, link),
+		}
+	})
+	if err != nil {
+		log.Printf("auth: failed to issue password reset token for user %d: %v", user.ID, err)
+	}
+}
+
+// ResetPassword godoc
+// @Summary Сбросить пароль
+// @Description Задает новый пароль по одноразовому токену из письма. Все сессии пользователя завершаются, блокировка входа по логину снимается.
+// @Tags auth
+// @Accept  json
+// @Produce  json
+// @Param   request body ResetPasswordRequest true "Токен и новый пароль"
+// @Success 200 {object} map[string]interface{} "Пароль изменен"
+// @Failure 400 {object} map[string]interface{} "Неверный или просроченный токен (code: invalid_token)"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /auth/reset-password [post]
+func ResetPassword(c *gin.Context, db *gorm.DB, throttler *throttle.Throttler) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 14)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	var user models.User
+	err = db.Transaction(func(tx *gorm.DB) error {
+		token, err := auth.ConsumeUserToken(tx, req.Token, models.TokenPasswordReset)
+		if err != nil {
+			return err
+		}
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			return err
+		}
+		// The reset link went to the user's mailbox, which proves the address
+		updates := map[string]interface{}{"password": string(hashedPassword)}
+		if user.EmailVerifiedAt == nil {
+			updates["email_verified_at"] = time.Now()
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		// Whoever knew the old password must not stay logged in
+		_, err = auth.RevokeUserSessions(tx, user.ID)
+		return err
+	})
+	if err == auth.ErrInvalidUserToken {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token", "code": errCodeInvalidToken})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if err := throttler.Reset(throttle.IdentifierKey(user.Identifier)); err != nil {
+		log.Printf("auth: failed to clear login lockout of user %d: %v", user.ID, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in"})
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RosterEntry is one expected student of a session. Students without a mark
+// are absent.
+type RosterEntry struct {
+	Student     models.User  `json:"student"`
+	Status      string       `json:"status"` // 'present', 'late', 'absent' or 'excused'
+	MinutesLate int          `json:"minutes_late"`
+	SubmittedAt *time.Time   `json:"submitted_at"` // Nil for students without a mark
+	Source      string       `json:"source"`       // 'code', 'manual' or 'excuse', empty for absent students
+	MarkedBy    *models.User `json:"marked_by,omitempty"`
+}
+
+// RosterCount is the number of roster entries with one status and their share
+// of all entries.
+type RosterCount struct {
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent" example:"87.5"`
+}
+
+type RosterTotals struct {
+	Expected int         `json:"expected"`
+	Present  RosterCount `json:"present"`
+	Late     RosterCount `json:"late"`
+	Absent   RosterCount `json:"absent"`
+	Excused  RosterCount `json:"excused"`
+}
+
+type RosterResponse struct {
+	Lesson   models.Lesson        `json:"lesson"`
+	Session  models.LessonSession `json:"session"`
+	Students []RosterEntry        `json:"students"`
+	Totals   RosterTotals         `json:"totals"`
+}
+
+// rosterSourceNames label mark sources in the CSV export.
+var rosterSourceNames = map[string]string{
+	models.AttendanceSourceCode:   "по коду",
+	models.AttendanceSourceManual: "вручную",
+	models.AttendanceSourceExcuse: "справка",
+}
+
+// rosterStatusNames label statuses in the CSV export.
+var rosterStatusNames = map[string]string{
+	models.AttendancePresent: "присутствовал",
+	models.AttendanceLate:    "опоздал",
+	models.AttendanceAbsent:  "отсутствовал",
+	models.AttendanceExcused: "уважительная причина",
+}
+
+// buildRoster joins the members of the lesson's group with the marks of the
+// session. Students who marked the session but have since left the group are
+// listed too. Absent students with an approved excuse are excused.
+func buildRoster(db *gorm.DB, lesson models.Lesson, session models.LessonSession) ([]RosterEntry, error) {
+	var attendance []models.Attendance
+	if err := db.Preload("Student").Preload("MarkedBy").Where("session_id = ?", session.ID).Find(&attendance).Error; err != nil {
+		return nil, err
+	}
+	marks := make(map[uint]models.Attendance, len(attendance))
+	for _, a := range attendance {
+		marks[a.StudentID] = a
+	}
+
+	var students []models.User
+	if lesson.GroupID != nil {
+		if err := db.Where("group_id = ? AND role = ?", *lesson.GroupID, "student").Order("name").Find(&students).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	studentIDs := make([]uint, 0, len(students))
+	for _, student := range students {
+		studentIDs = append(studentIDs, student.ID)
+	}
+	excuses, err := approvedExcuses(db, studentIDs, session.Date, session.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := make([]RosterEntry, 0, len(students)+len(attendance))
+	for _, student := range students {
+		entry := RosterEntry{Student: student, Status: models.AttendanceAbsent}
+		if len(excuses[student.ID]) > 0 {
+			entry.Status = models.AttendanceExcused
+			entry.Source = models.AttendanceSourceExcuse
+		}
+		if mark, ok := marks[student.ID]; ok {
+			entry.Status = mark.Status
+			entry.MinutesLate = mark.MinutesLate
+			entry.Source = mark.Source
+			entry.MarkedBy = mark.MarkedBy
+			submittedAt := mark.SubmittedAt
+			entry.SubmittedAt = &submittedAt
+			delete(marks, student.ID)
+		}
+		roster = append(roster, entry)
+	}
+	for _, a := range attendance {
+		if _, left := marks[a.StudentID]; !left {
+			continue
+		}
+		submittedAt := a.SubmittedAt
+		roster = append(roster, RosterEntry{
+			Student:     a.Student,
+			Status:      a.Status,
+			MinutesLate: a.MinutesLate,
+			SubmittedAt: &submittedAt,
+			Source:      a.Source,
+			MarkedBy:    a.MarkedBy,
+		})
+	}
+	return roster, nil
+}
+
+func rosterTotals(roster []RosterEntry) RosterTotals {
+	totals := RosterTotals{Expected: len(roster)}
+	for _, entry := range roster {
+		switch entry.Status {
+		case models.AttendancePresent:
+			totals.Present.Count++
+		case models.AttendanceLate:
+			totals.Late.Count++
+		case models.AttendanceExcused:
+			totals.Excused.Count++
+		default:
+			totals.Absent.Count++
+		}
+	}
+	for _, count := range []*RosterCount{&totals.Present, &totals.Late, &totals.Absent, &totals.Excused} {
+		if totals.Expected > 0 {
+			count.Percent = math.Round(float64(count.Count)/float64(totals.Expected)*1000) / 10
+		}
+	}
+	return totals
+}
+
+// csvCell keeps spreadsheet programs from running user supplied text as a
+// formula by prefixing cells that start like one with an apostrophe.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// writeRosterCSV sends the roster as a CSV file for printing or spreadsheets.
+func writeRosterCSV(c *gin.Context, response RosterResponse) {
+	filename := fmt.Sprintf("roster-%d-%s.csv", response.Lesson.ID, response.Session.Date.Format("2006-01-02"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	// The byte order mark makes spreadsheet programs read the file as UTF-8
+	c.Writer.WriteString("\ufeff")
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{csvCell(response.Lesson.Name), response.Session.Date.Format("02.01.2006"), csvCell(response.Lesson.Time), csvCell(response.Lesson.Room)})
+	w.Write([]string{"Студент", "Логин", "Статус", "Опоздание, мин", "Время отметки", "Отметка"})
+	for _, entry := range response.Students {
+		submittedAt := ""
+		if entry.SubmittedAt != nil {
+			submittedAt = entry.SubmittedAt.Local().Format("15:04:05")
+		}
+		minutesLate := ""
+		if entry.Status == models.AttendanceLate {
+			minutesLate = fmt.Sprint(entry.MinutesLate)
+		}
+		w.Write([]string{csvCell(entry.Student.Name), csvCell(entry.Student.Identifier), rosterStatusNames[entry.Status], minutesLate, submittedAt, rosterSourceNames[entry.Source]})
+	}
+	totals := response.Totals
+	w.Write([]string{})
+	w.Write([]string{"Всего", fmt.Sprint(totals.Expected)})
+	for _, row := range []struct {
+		status string
+		count  RosterCount
+	}{
+		{models.AttendancePresent, totals.Present},
+		{models.AttendanceLate, totals.Late},
+		{models.AttendanceAbsent, totals.Absent},
+		{models.AttendanceExcused, totals.Excused},
+	} {
+		w.Write([]string{rosterStatusNames[row.status], fmt.Sprint(row.count.Count), fmt.Sprintf("%.1f%%", row.count.Percent)})
+	}
+	w.Flush()
+}
+
+// GetLessonRoster godoc
+// @Summary Получить полный список группы на занятии
+// @Description Возвращает всех студентов группы занятия со статусом в одной сессии (present, late, absent или excused), итоги и проценты по статусам. Студенты без отметки считаются отсутствующими; source показывает, отмечен ли студент по коду (code) или преподавателем вручную (manual). Сессия выбирается по session_id или дате, по умолчанию - последняя. С format=csv возвращает файл для печати.
+// @Tags teacher
+// @Produce  json
+// @Produce  text/csv
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param session_id query int false "ID Сессии"
+// @Param date query string false "Дата сессии (YYYY-MM-DD)"
+// @Param format query string false "json (по умолчанию) или csv"
+// @Success 200 {object} RosterResponse "Список группы"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 404 {object} map[string]interface{} "Занятие или сессия не найдены"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/roster [get]
+func GetLessonRoster(c *gin.Context, db *gorm.DB) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+	if !requireLessonRead(c, db, lessonID) {
+		return
+	}
+
+	var lesson models.Lesson
+	if err := db.Preload("Group").First(&lesson, lessonID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lesson not found"})
+		return
+	}
+	session, err := findSession(db, lessonID, c.Query("session_id"), c.Query("date"))
+	if err == errInvalidDate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve session"})
+		return
+	}
+
+	roster, err := buildRoster(db, lesson, session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build roster"})
+		return
+	}
+	response := RosterResponse{Lesson: lesson, Session: session, Students: roster, Totals: rosterTotals(roster)}
+	if format == "csv" {
+		writeRosterCSV(c, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errInvalidDate = errors.New("invalid date, expected YYYY-MM-DD")
+
+// findSession resolves a session of the lesson from the session_id or date
+// query values, falling back to the most recent session of the lesson.
+func findSession(db *gorm.DB, lessonID uint, sessionID, date string) (models.LessonSession, error) {
+	var session models.LessonSession
+	query := db.Where("lesson_id = ?", lessonID)
+	switch {
+	case sessionID != "":
+		query = query.Where("id = ?", sessionID)
+	case date != "":
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return session, errInvalidDate
+		}
+		query = query.Where("date = ?", day)
+	}
+	err := query.Order("date desc").First(&session).Error
+	return session, err
+}
+
+// scopeToTerm restricts an attendance query to sessions held during the term
+// given by the term_id query parameter. It writes a 400 response and returns
+// false when the term does not exist.
+func scopeToTerm(c *gin.Context, db *gorm.DB) (*gorm.DB, bool) {
+	termID := c.Query("term_id")
+	if termID == "" {
+		return db, true
+	}
+	var term models.Term
+	if err := db.First(&term, termID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Term not found"})
+		return nil, false
+	}
+	return db.Where("session_id IN (?)", db.Model(&models.LessonSession{}).
+		Select("id").
+		Where("date BETWEEN ? AND ?", term.StartDate, term.EndDate)), true
+}
+
+// paramUint parses a numeric path parameter.
+func paramUint(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	return uint(id), err == nil
+}
+
+// GetLessonSessions godoc
+// @Summary Получить проведенные занятия
+// @Description Возвращает список датированных занятий (сессий), созданных по недельному расписанию занятия.
+// @Tags teacher
+// @Produce  json
+// @Security BearerAuth
+// @Param lessonId path int true "ID Занятия"
+// @Param term_id query int false "ID Семестра"
+// @Success 200 {array} models.LessonSession "Список сессий"
+// @Failure 400 {object} map[string]interface{} "Неверный запрос"
+// @Failure 403 {object} map[string]interface{} "Нет доступа к занятию"
+// @Failure 500 {object} map[string]interface{} "Внутренняя ошибка сервера"
+// @Router /api/teacher/lessons/{lessonId}/sessions [get]
+func GetLessonSessions(c *gin.Context, db *gorm.DB) {
+	lessonID, ok := paramUint(c, "lessonId")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lesson ID"})
+		return
+	}
+	if !requireLessonRead(c, db, lessonID) {
+		return
+	}
+
+	query := db
+	if termID := c.Query("term_id"); termID != "" {
+		var term models.Term
+		if err := db.First(&term, termID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Term not found"})
+			return
+		}
+		query = query.Where("date BETWEEN ? AND ?", term.StartDate, term.EndDate)
+	}
+
+	var sessions []models.LessonSession
+	if err := query.Where("lesson_id = ?", lessonID).Order("date desc").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
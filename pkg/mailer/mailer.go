@@ -0,0 +1,140 @@
+// Package mailer sends the emails of the account flows, such as password
+// reset links.
+package mailer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Transports a Mailer can use.
+const (
+	// TransportLog writes messages to the server log.
+	TransportLog = "log"
+	// TransportFile writes every message to its own file in a directory.
+	TransportFile = "file"
+	// TransportSMTP delivers messages through an SMTP server.
+	TransportSMTP = "smtp"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends messages.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Errors returned by Send for messages that would produce a malformed or
+// injected header.
+var (
+	ErrInvalidAddress = errors.New("mailer: invalid recipient address")
+	ErrInvalidSubject = errors.New("mailer: line break in subject")
+)
+
+// ValidAddress reports whether address is a single bare email address such
+// as "name@example.com", without a display name or line breaks that could
+// inject headers.
+func ValidAddress(address string) bool {
+	parsed, err := mail.ParseAddress(address)
+	return err == nil && parsed.Address == address
+}
+
+// format renders msg as an RFC 5322 message. It rejects recipients and
+// subjects that would break out of their header line.
+func format(from string, msg Message) ([]byte, error) {
+	if !ValidAddress(msg.To) {
+		return nil, ErrInvalidAddress
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, ErrInvalidSubject
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mimeHeader(msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String()), nil
+}
+
+// mimeHeader encodes a non-ASCII header value, as subjects are in Russian.
+func mimeHeader(value string) string {
+	for _, r := range value {
+		if r > 127 {
+			return "=?UTF-8?B?" + base64.StdEncoding.EncodeToString([]byte(value)) + "?="
+		}
+	}
+	return value
+}
+
+// SMTPMailer delivers messages through an SMTP server with PLAIN auth.
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	data, err := format(m.From, msg)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	addr := fmt.Sprintf("%s:%d", m.Host, m.Port)
+	return smtp.SendMail(addr, auth, m.From, []string{msg.To}, data)
+}
+
+// LogMailer writes messages to the server log, or to files in Dir when it is
+// set. It is meant for local development and tests, where the links in the
+// messages can be copied from the output.
+type LogMailer struct {
+	From string
+	Dir  string
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	data, err := format(m.From, msg)
+	if err != nil {
+		return err
+	}
+	if m.Dir == "" {
+		log.Printf("mailer: to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
+
+// sanitize makes an address safe to use in a file name.
+func sanitize(address string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+		return r
+	}, address)
+}
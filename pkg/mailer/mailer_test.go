@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{"student001@example.com", true},
+		{"", false},
+		{"not an address", false},
+		{"Student <student001@example.com>", false},
+		{"student001@example.com, admin@example.com", false},
+		{"student001@example.com\r\nBcc: admin@example.com", false},
+	}
+	for _, tt := range tests {
+		if got := ValidAddress(tt.address); got != tt.want {
+			t.Errorf("ValidAddress(%q) = %v, want %v", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRejectsHeaderInjection(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want error
+	}{
+		{"line break in recipient", Message{To: "student001@example.com\r\nBcc: admin@example.com", Subject: "Reset"}, ErrInvalidAddress},
+		{"line break in subject", Message{To: "student001@example.com", Subject: "Reset\r\nBcc: admin@example.com"}, ErrInvalidSubject},
+		{"bare line feed in subject", Message{To: "student001@example.com", Subject: "Reset\nBcc: admin@example.com"}, ErrInvalidSubject},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := format("noreply@example.com", tt.msg); err != tt.want {
+				t.Errorf("format error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	data, err := format("noreply@example.com", Message{To: "student001@example.com", Subject: "Сброс пароля", Body: "line 1\nline 2"})
+	if err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	header, body, ok := strings.Cut(string(data), "\r\n\r\n")
+	if !ok {
+		t.Fatalf("no blank line between header and body in %q", data)
+	}
+	if !strings.Contains(header, "To: student001@example.com\r\n") {
+		t.Errorf("header %q has no To line", header)
+	}
+	if !strings.Contains(header, "Subject: =?UTF-8?B?") {
+		t.Errorf("header %q has no encoded Subject line", header)
+	}
+	if body != "line 1\r\nline 2" {
+		t.Errorf("body = %q, want CRLF line endings", body)
+	}
+}
+
+func TestLogMailerRejectsInvalidAddress(t *testing.T) {
+	dir := t.TempDir()
+	m := &LogMailer{From: "noreply@example.com", Dir: dir}
+	if err := m.Send(Message{To: "../student001@example.com\r\nBcc: admin@example.com", Subject: "Reset"}); err != ErrInvalidAddress {
+		t.Fatalf("Send error = %v, want %v", err, ErrInvalidAddress)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Send wrote %d files for an invalid address", len(entries))
+	}
+}
@@ -2,15 +2,17 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 	"student-attendance-app/pkg/auth"
 	"student-attendance-app/pkg/config"
-	"strings"
+	"student-attendance-app/pkg/rbac"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+func AuthMiddleware(cfg *config.Config, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -32,19 +34,51 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Tokens of revoked sessions, and tokens issued before sessions
+		// existed, are no longer accepted
+		sessionID, ok := claims["sid"].(float64)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+		active, err := auth.SessionActive(db, uint(sessionID))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check session"})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			return
+		}
+
+		userID, ok := claims["id"].(float64)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			return
+		}
+		// Loaded on every request so that role changes apply immediately
+		permissions, err := rbac.UserPermissions(db, uint(userID))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to load permissions"})
+			return
+		}
+
 		c.Set("userID", claims["id"])
 		c.Set("userRole", claims["role"])
+		c.Set("sessionID", uint(sessionID))
+		rbac.SetPermissions(c, permissions)
 		c.Next()
 	}
 }
 
-func RoleMiddleware(requiredRole string) gin.HandlerFunc {
+// PermissionMiddleware lets the request through if the user holds any of
+// the permissions. It must run after AuthMiddleware.
+func PermissionMiddleware(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("userRole")
-		if !exists || userRole.(string) != requiredRole {
+		if !rbac.Has(c, permissions...) {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource"})
 			return
 		}
 		c.Next()
 	}
-} 
\ No newline at end of file
+}
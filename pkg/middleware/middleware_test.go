@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"student-attendance-app/pkg/rbac"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPermissionMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name     string
+		held     map[string]bool
+		required []string
+		want     int
+	}{
+		{"holds the permission", map[string]bool{rbac.CodesGenerate: true}, []string{rbac.CodesGenerate}, http.StatusOK},
+		{"holds one of the permissions", map[string]bool{rbac.AttendanceReadAll: true}, []string{rbac.AttendanceReadLesson, rbac.AttendanceReadAll}, http.StatusOK},
+		{"holds another permission", map[string]bool{rbac.AttendanceSubmit: true}, []string{rbac.CodesGenerate}, http.StatusForbidden},
+		{"holds no permissions", map[string]bool{}, []string{rbac.CodesGenerate}, http.StatusForbidden},
+		{"permissions were never loaded", nil, []string{rbac.CodesGenerate}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/", func(c *gin.Context) {
+				if tt.held != nil {
+					rbac.SetPermissions(c, tt.held)
+				}
+			}, PermissionMiddleware(tt.required...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.want {
+				t.Errorf("got status %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
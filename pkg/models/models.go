@@ -3,53 +3,352 @@ package models
 import "time"
 
 type Group struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"unique;not null" json:"name"`
+	// CuratorID is the staff member (куратор) who follows the group's
+	// attendance. No foreign key, since users already reference groups.
+	CuratorID *uint     `gorm:"index" json:"curator_id"`
+	Curator   *User     `gorm:"foreignKey:CuratorID;constraint:-" json:"curator,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type User struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	Identifier string `gorm:"unique;not null" json:"identifier"`
+	Password   string `gorm:"not null" json:"-"` // Omit from JSON responses
+	Name       string `gorm:"not null" json:"name"`
+	Email      string `gorm:"unique;not null" json:"email"`
+	Role       string `gorm:"not null" json:"role"`                  // 'student', 'teacher', 'admin' or 'guardian'
+	Status     string `gorm:"not null;default:active" json:"status"` // 'active', 'pending' or 'rejected'
+	GroupID    *uint  `json:"group_id"`
+	Group      Group  `gorm:"foreignKey:GroupID;references:ID" json:"group"`
+	// Nil until the user opens the link mailed on registration
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// Roles grant permissions; Role above is the primary one the frontend
+	// picks a portal by
+	Roles     []Role    `gorm:"many2many:user_roles" json:"roles,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Account states. Pending and rejected accounts cannot log in.
+const (
+	UserActive   = "active"
+	UserPending  = "pending"
+	UserRejected = "rejected"
+)
+
+// Role is a named set of permissions, see the rbac package. System roles
+// are the built-in student, teacher and admin roles and cannot be deleted.
+type Role struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	Name        string           `gorm:"unique;not null" json:"name"`
+	Description string           `json:"description"`
+	System      bool             `gorm:"not null" json:"system"`
+	Permissions []RolePermission `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// RolePermission grants one permission to a role.
+type RolePermission struct {
+	RoleID     uint   `gorm:"primaryKey" json:"role_id"`
+	Permission string `gorm:"primaryKey" json:"permission"`
+}
+
+// GroupInvite lets students register into its group without choosing it.
+// MaxUses and ExpiresAt are optional limits.
+type GroupInvite struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	GroupID     uint       `gorm:"not null;index" json:"group_id"`
+	Code        string     `gorm:"not null;uniqueIndex" json:"code"`
+	CreatedByID uint       `gorm:"not null" json:"created_by_id"`
+	MaxUses     *int       `json:"max_uses"`
+	Uses        int        `gorm:"not null" json:"uses"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Group       Group      `gorm:"foreignKey:GroupID;references:ID" json:"group"`
+}
+
+// AuthSession is one login of a user on one device. Access tokens carry its
+// ID and stop working as soon as RevokedAt is set.
+type AuthSession struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `gorm:"not null" json:"last_used_at"` // Last refresh
+	RevokedAt  *time.Time `json:"revoked_at"`
+	User       User       `gorm:"foreignKey:UserID;references:ID" json:"-"`
+}
+
+// RefreshToken is a single-use token exchanging into a new access token and
+// a new refresh token. Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uint        `gorm:"primaryKey" json:"id"`
+	SessionID uint        `gorm:"not null;index" json:"session_id"`
+	TokenHash string      `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time   `gorm:"not null;index" json:"expires_at"`
+	UsedAt    *time.Time  `json:"used_at"` // Set once rotated; using it again revokes the session
+	CreatedAt time.Time   `json:"created_at"`
+	Session   AuthSession `gorm:"foreignKey:SessionID;references:ID" json:"-"`
+}
+
+// Purposes of a UserToken.
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+)
+
+// UserToken is a single-use token mailed to a user, e.g. in a password reset
+// link. Only a hash of the token is stored.
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"not null" json:"purpose"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	User      User       `gorm:"foreignKey:UserID;references:ID" json:"-"`
+}
+
+// GuardianLink gives a guardian (parent) read-only access to a student's
+// attendance.
+type GuardianLink struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	GuardianID  uint      `gorm:"not null;uniqueIndex:idx_guardian_link_pair" json:"guardian_id"`
+	StudentID   uint      `gorm:"not null;uniqueIndex:idx_guardian_link_pair;index" json:"student_id"`
+	CreatedByID uint      `gorm:"not null" json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Guardian    User      `gorm:"foreignKey:GuardianID;references:ID" json:"guardian"`
+	Student     User      `gorm:"foreignKey:StudentID;references:ID" json:"student"`
+}
+
+// GuardianInvite is a mailed invitation to become a student's guardian,
+// sent by the student or an admin. Only a hash of the token is stored.
+type GuardianInvite struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	StudentID   uint       `gorm:"not null;index" json:"student_id"`
+	Email       string     `gorm:"not null" json:"email"`
+	CreatedByID uint       `gorm:"not null" json:"created_by_id"`
+	TokenHash   string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Student     User       `gorm:"foreignKey:StudentID;references:ID" json:"-"`
+}
+
+// Term is an academic semester. Lessons bound to a term only take place
+// between its start and end dates, except on its holidays.
+type Term struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	Name      string    `gorm:"unique;not null" json:"name"`
+	StartDate time.Time `gorm:"type:date;not null" json:"start_date"`
+	EndDate   time.Time `gorm:"type:date;not null" json:"end_date"`
+	Holidays  []Holiday `json:"holidays,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type User struct {
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	Identifier string    `gorm:"unique;not null" json:"identifier"`
-	Password   string    `gorm:"not null" json:"-"` // Omit from JSON responses
-	Name       string    `gorm:"not null" json:"name"`
-	Email      string    `gorm:"unique;not null" json:"email"`
-	Role       string    `gorm:"not null" json:"role"` // 'student', 'teacher', or 'admin'
-	GroupID    *uint     `json:"group_id"`
-	Group      Group     `gorm:"foreignKey:GroupID;references:ID" json:"group"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+// Holiday is a non-teaching day within a term.
+type Holiday struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TermID    uint      `gorm:"not null;uniqueIndex:idx_holiday_term_date" json:"term_id"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex:idx_holiday_term_date" json:"date"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
+// Week parities of a lesson: every week, odd weeks only (числитель) or even
+// weeks only (знаменатель).
+const (
+	WeekEvery = "every"
+	WeekOdd   = "odd"
+	WeekEven  = "even"
+)
+
+// Lesson is a weekly timetable slot. Name, Day and Time are unique per week
+// parity and term, see idx_lesson_name_day_time_parity_term in the database
+// package.
 type Lesson struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	Name       string          `json:"name"`
+	Day        string          `json:"day"`
+	Time       string          `json:"time"`
+	WeekParity string          `gorm:"not null;default:every" json:"week_parity"` // 'every', 'odd' or 'even'
+	Teacher    string          `json:"teacher"`                                   // Display name of the lead teachers, see Teachers
+	Room       string          `json:"room"`
+	GroupID    *uint           `json:"group_id"`
+	Group      Group           `json:"group"`
+	TermID     *uint           `json:"term_id"`
+	Term       Term            `json:"term"`
+	Teachers   []LessonTeacher `json:"teachers"`
+	// Overrides of the attendance code defaults from config.Config
+	CodeLength     *int      `json:"code_length"`
+	CodeAlphabet   *string   `json:"code_alphabet"` // 'digits' or 'alphanumeric'
+	CodeTTLSeconds *int      `json:"code_ttl_seconds"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Roles of a teacher assigned to a lesson.
+const (
+	LessonTeacherLead      = "lead"
+	LessonTeacherAssistant = "assistant"
+)
+
+// LessonTeacher assigns a teacher account to a lesson.
+type LessonTeacher struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"uniqueIndex:idx_lesson_name_day_time" json:"name"`
-	Day       string    `gorm:"uniqueIndex:idx_lesson_name_day_time" json:"day"`
-	Time      string    `gorm:"uniqueIndex:idx_lesson_name_day_time" json:"time"`
-	Teacher   string    `json:"teacher"`
-	Room      string    `json:"room"`
-	GroupID   *uint     `json:"group_id"`
-	Group     Group     `json:"group"`
+	LessonID  uint      `gorm:"not null;uniqueIndex:idx_lesson_teacher" json:"lesson_id"`
+	TeacherID uint      `gorm:"not null;uniqueIndex:idx_lesson_teacher;index" json:"teacher_id"`
+	Role      string    `gorm:"not null;default:lead" json:"role"` // 'lead' or 'assistant'
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Teacher   User      `gorm:"foreignKey:TeacherID;references:ID" json:"teacher"`
+}
+
+// LessonDelegation grants a teacher who is not assigned to a lesson the same
+// rights on it as its assigned teachers, e.g. for a substitute.
+type LessonDelegation struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	LessonID    uint       `gorm:"not null;index" json:"lesson_id"`
+	GranteeID   uint       `gorm:"not null;index" json:"grantee_id"`
+	GrantedByID uint       `gorm:"not null" json:"granted_by_id"`
+	ExpiresAt   *time.Time `json:"expires_at"` // Never expires when nil
+	CreatedAt   time.Time  `json:"created_at"`
+	Grantee     User       `gorm:"foreignKey:GranteeID;references:ID" json:"grantee"`
 }
 
+// LessonSession is a single dated occurrence of a weekly Lesson.
+type LessonSession struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	LessonID   uint      `gorm:"not null;uniqueIndex:idx_session_lesson_date" json:"lesson_id"`
+	Date       time.Time `gorm:"type:date;not null;uniqueIndex:idx_session_lesson_date" json:"date"`
+	StartsAt   time.Time `gorm:"not null" json:"starts_at"`
+	EndsAt     time.Time `gorm:"not null" json:"ends_at"`
+	CodeSecret []byte    `json:"-"` // Seed of rotating codes, set on first use
+	CreatedAt  time.Time `json:"created_at"`
+	Lesson     Lesson    `gorm:"foreignKey:LessonID;references:ID" json:"lesson"`
+}
+
+// Attendance statuses. Late marks were submitted after the session's start
+// plus the grace period of config.Config.
+const (
+	AttendancePresent = "present"
+	AttendanceLate    = "late"
+	AttendanceAbsent  = "absent"
+	AttendanceExcused = "excused"
+)
+
+// Sources of an attendance mark: submitted by the student with a code or set
+// by a teacher. Absences covered by an approved ExcuseRequest are reported as
+// excused with AttendanceSourceExcuse, but are not stored as marks.
+const (
+	AttendanceSourceCode   = "code"
+	AttendanceSourceManual = "manual"
+	AttendanceSourceExcuse = "excuse"
+)
+
 type Attendance struct {
+	ID          uint          `gorm:"primaryKey" json:"id"`
+	LessonID    uint          `gorm:"not null" json:"lesson_id"`
+	SessionID   *uint         `gorm:"uniqueIndex:idx_attendance_session_student" json:"session_id"`
+	StudentID   uint          `gorm:"not null;uniqueIndex:idx_attendance_session_student" json:"student_id"`
+	SubmittedAt time.Time     `gorm:"not null" json:"submitted_at"`
+	Status      string        `gorm:"not null;default:present" json:"status"` // 'present', 'late', 'absent' or 'excused'
+	MinutesLate int           `gorm:"not null;default:0" json:"minutes_late"` // Minutes after the scheduled start, for late marks
+	Source      string        `gorm:"not null;default:code" json:"source"`    // 'code' or 'manual'
+	MarkedByID  *uint         `json:"marked_by_id"`                           // Teacher who last set the mark manually
+	Lesson      Lesson        `gorm:"foreignKey:LessonID;references:ID" json:"lesson"`
+	Session     LessonSession `gorm:"foreignKey:SessionID;references:ID" json:"session"`
+	Student     User          `gorm:"foreignKey:StudentID;references:ID" json:"student"`
+	MarkedBy    *User         `gorm:"foreignKey:MarkedByID;constraint:OnDelete:SET NULL" json:"marked_by,omitempty"`
+}
+
+// AttendanceChange records a teacher marking, correcting or removing a
+// student's attendance of a session.
+type AttendanceChange struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
-	LessonID    uint      `gorm:"not null" json:"lesson_id"`
-	StudentID   uint      `gorm:"not null" json:"student_id"`
-	SubmittedAt time.Time `gorm:"not null" json:"submitted_at"`
-	Lesson      Lesson    `gorm:"foreignKey:LessonID;references:ID" json:"lesson"`
+	LessonID    uint      `gorm:"not null;index" json:"lesson_id"`
+	SessionID   uint      `gorm:"not null;index" json:"session_id"`
+	StudentID   uint      `gorm:"not null;index" json:"student_id"`
+	OldStatus   string    `json:"old_status"` // Empty when the student had no mark
+	NewStatus   string    `json:"new_status"` // Empty when the mark was removed
+	Reason      string    `gorm:"not null" json:"reason"`
+	ChangedByID *uint     `json:"changed_by_id"` // Nil once the teacher's account is deleted
+	ChangedAt   time.Time `gorm:"not null" json:"changed_at"`
 	Student     User      `gorm:"foreignKey:StudentID;references:ID" json:"student"`
+	ChangedBy   *User     `gorm:"foreignKey:ChangedByID;constraint:OnDelete:SET NULL" json:"changed_by,omitempty"`
+}
+
+// Statuses of an ExcuseRequest.
+const (
+	ExcusePending  = "pending"
+	ExcuseApproved = "approved"
+	ExcuseRejected = "rejected"
+)
+
+// ExcuseRequest is a student's excuse for missing the sessions between
+// StartDate and EndDate, such as a medical certificate. Once approved, the
+// student's absences in that range count as excused.
+type ExcuseRequest struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	StudentID     uint       `gorm:"not null;index" json:"student_id"`
+	StartDate     time.Time  `gorm:"type:date;not null" json:"start_date"`
+	EndDate       time.Time  `gorm:"type:date;not null" json:"end_date"`
+	Reason        string     `json:"reason"`
+	FileKey       string     `gorm:"not null" json:"-"` // Key of the attached document in storage.Storage
+	FileName      string     `gorm:"not null" json:"file_name"`
+	ContentType   string     `gorm:"not null" json:"content_type"` // 'application/pdf' or 'image/jpeg'
+	FileSize      int64      `gorm:"not null" json:"file_size"`
+	Status        string     `gorm:"not null;default:pending;index" json:"status"` // 'pending', 'approved' or 'rejected'
+	ReviewedByID  *uint      `json:"reviewed_by_id"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewComment string     `json:"review_comment"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Student       User       `gorm:"foreignKey:StudentID;references:ID" json:"student"`
+	ReviewedBy    *User      `gorm:"foreignKey:ReviewedByID;constraint:OnDelete:SET NULL" json:"reviewed_by,omitempty"`
+}
+
+// CodeAttempt counts a student's wrong codes for one lesson session. After a
+// few free failures the student is locked out until LockedUntil.
+type CodeAttempt struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	SessionID    uint       `gorm:"not null;uniqueIndex:idx_code_attempt_session_student" json:"session_id"`
+	StudentID    uint       `gorm:"not null;uniqueIndex:idx_code_attempt_session_student" json:"student_id"`
+	Failures     int        `gorm:"not null" json:"failures"`
+	LastFailedAt time.Time  `gorm:"not null" json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+	Student      User       `gorm:"foreignKey:StudentID;references:ID" json:"student"`
 }
 
+// LoginThrottle is the failed login counter of one identifier or client IP,
+// used by the Postgres throttle store.
+type LoginThrottle struct {
+	Key          string     `gorm:"primaryKey" json:"key"` // 'identifier:<login>' or 'ip:<address>'
+	Failures     int        `gorm:"not null" json:"failures"`
+	LastFailedAt time.Time  `gorm:"not null;index" json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+// GeneratedCode opens a lesson session for attendance until ExpiresAt. Static
+// codes store their value in Code; rotating codes leave it empty in the
+// database and derive it from the session's CodeSecret every StepSeconds.
 type GeneratedCode struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	LessonID  uint      `gorm:"not null" json:"lesson_id"`
-	Code      string    `gorm:"not null" json:"code"`
-	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
-	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	Lesson    Lesson    `gorm:"foreignKey:LessonID;references:ID" json:"lesson"`
+	ID          uint          `gorm:"primaryKey" json:"id"`
+	LessonID    uint          `gorm:"not null" json:"lesson_id"`
+	SessionID   *uint         `gorm:"index" json:"session_id"`
+	Mode        string        `gorm:"not null;default:static" json:"mode"` // 'static' or 'rotating'
+	Code        string        `gorm:"not null;uniqueIndex:idx_generated_codes_active_static_code,where:is_active AND mode = 'static'" json:"code"`
+	StepSeconds int           `json:"step_seconds,omitempty"`
+	RotatesAt   *time.Time    `gorm:"-" json:"rotates_at,omitempty"` // When the current rotating value changes
+	ExpiresAt   time.Time     `gorm:"not null" json:"expires_at"`
+	IsActive    bool          `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Lesson      Lesson        `gorm:"foreignKey:LessonID;references:ID" json:"lesson"`
+	Session     LessonSession `gorm:"foreignKey:SessionID;references:ID" json:"session"`
 }
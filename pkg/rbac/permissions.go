@@ -0,0 +1,91 @@
+// Package rbac resolves what a user may do. Users hold any number of roles,
+// and every role is a named set of permissions stored in the database.
+package rbac
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Permissions checked by the API.
+const (
+	AttendanceSubmit     = "attendance.submit"      // Mark one's own attendance with a code
+	AttendanceReadOwn    = "attendance.read.own"    // Read one's own attendance
+	AttendanceReadLesson = "attendance.read.lesson" // Read attendance of lessons one teaches
+	AttendanceReadGroup  = "attendance.read.group"  // Read rosters and attendance of groups one curates
+	AttendanceReadLinked = "attendance.read.linked" // Read attendance of students one is a guardian of
+	AttendanceReadAll    = "attendance.read.all"    // Read attendance of every lesson
+	CodesGenerate        = "codes.generate"         // Open and close attendance codes of lessons one teaches
+	ExcusesSubmit        = "excuses.submit"         // Submit and withdraw one's own absence excuses
+	ExcusesReview        = "excuses.review"         // Approve or reject excuses of students one may read
+	LessonsTeach         = "lessons.teach"          // List one's lessons and delegate them
+	LessonsManage        = "lessons.manage"         // Edit the timetable; act on every lesson as its teacher
+	GroupsManage         = "groups.manage"          // Edit groups and their invite codes
+	GroupsInvite         = "groups.invite"          // Create and revoke invite codes of groups one curates
+	TermsManage          = "terms.manage"           // Edit terms and holidays
+	UsersManage          = "users.manage"           // Edit users, registrations, sessions and lockouts
+	RolesManage          = "roles.manage"           // Edit roles and assign them to users
+)
+
+// descriptions lists every known permission.
+var descriptions = map[string]string{
+	AttendanceSubmit:     "Отмечать свое посещение кодом",
+	AttendanceReadOwn:    "Просматривать свою посещаемость",
+	AttendanceReadLesson: "Просматривать посещаемость своих занятий",
+	AttendanceReadGroup:  "Просматривать состав и посещаемость курируемых групп",
+	AttendanceReadLinked: "Просматривать посещаемость привязанных студентов (для родителей)",
+	AttendanceReadAll:    "Просматривать посещаемость всех занятий",
+	CodesGenerate:        "Открывать и закрывать коды посещаемости своих занятий",
+	ExcusesSubmit:        "Подавать и отзывать свои объяснительные (справки)",
+	ExcusesReview:        "Одобрять и отклонять объяснительные студентов курируемых групп",
+	LessonsTeach:         "Просматривать и делегировать свои занятия",
+	LessonsManage:        "Редактировать расписание и работать с любым занятием как преподаватель",
+	GroupsManage:         "Редактировать группы и коды приглашения",
+	GroupsInvite:         "Создавать и отзывать коды приглашения в курируемые группы",
+	TermsManage:          "Редактировать семестры и каникулы",
+	UsersManage:          "Управлять пользователями, регистрациями, сессиями и блокировками",
+	RolesManage:          "Управлять ролями и назначать их пользователям",
+}
+
+// Permission describes a permission for the role editor.
+type Permission struct {
+	Name        string `json:"name" example:"codes.generate"`
+	Description string `json:"description"`
+}
+
+// All returns every known permission sorted by name.
+func All() []Permission {
+	all := make([]Permission, 0, len(descriptions))
+	for name, description := range descriptions {
+		all = append(all, Permission{Name: name, Description: description})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// IsKnown reports whether name is a permission the API checks.
+func IsKnown(name string) bool {
+	_, ok := descriptions[name]
+	return ok
+}
+
+// contextKey is where AuthMiddleware stores the current user's permissions.
+const contextKey = "permissions"
+
+// SetPermissions stores the current user's permissions in the request.
+func SetPermissions(c *gin.Context, permissions map[string]bool) {
+	c.Set(contextKey, permissions)
+}
+
+// Has reports whether the current user holds any of the permissions.
+func Has(c *gin.Context, permissions ...string) bool {
+	value, _ := c.Get(contextKey)
+	held, _ := value.(map[string]bool)
+	for _, permission := range permissions {
+		if held[permission] {
+			return true
+		}
+	}
+	return false
+}
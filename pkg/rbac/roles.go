@@ -0,0 +1,153 @@
+package rbac
+
+import (
+	"errors"
+	"student-attendance-app/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Built-in roles. All but curator are also values of models.User.Role.
+const (
+	RoleStudent  = "student"
+	RoleTeacher  = "teacher"
+	RoleAdmin    = "admin"
+	RoleCurator  = "curator" // Given on top of another role, e.g. teacher
+	RoleGuardian = "guardian"
+)
+
+// ErrUnknownRole is returned when assigning a role that does not exist.
+var ErrUnknownRole = errors.New("unknown role")
+
+// builtinRoles are created on startup when missing. Their permissions can be
+// changed afterwards, except that admin always keeps RolesManage so that
+// roles can still be repaired.
+var builtinRoles = []struct {
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{RoleStudent, "Студент", []string{AttendanceSubmit, AttendanceReadOwn, ExcusesSubmit}},
+	{RoleTeacher, "Преподаватель", []string{LessonsTeach, CodesGenerate, AttendanceReadLesson}},
+	{RoleCurator, "Куратор группы", []string{AttendanceReadGroup, GroupsInvite, ExcusesReview}},
+	{RoleGuardian, "Родитель", []string{AttendanceReadLinked}},
+	{RoleAdmin, "Администратор", []string{
+		LessonsTeach, CodesGenerate, AttendanceReadLesson, AttendanceReadAll, ExcusesReview,
+		LessonsManage, GroupsManage, TermsManage, UsersManage, RolesManage,
+	}},
+}
+
+// IsBuiltin reports whether name is a role created by Seed.
+func IsBuiltin(name string) bool {
+	for _, role := range builtinRoles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Seed creates the missing built-in roles and gives every user without
+// roles the built-in role named by their Role field.
+//
+// Existing built-in roles keep their edited permissions, but a permission no
+// role holds yet is new to the database, e.g. one added by an upgrade, and is
+// granted to the built-in roles that list it.
+func Seed(db *gorm.DB) error {
+	var granted []string
+	if err := db.Model(&models.RolePermission{}).Distinct("permission").Pluck("permission", &granted).Error; err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(granted))
+	for _, permission := range granted {
+		known[permission] = true
+	}
+
+	for _, builtin := range builtinRoles {
+		role := models.Role{Name: builtin.Name, Description: builtin.Description, System: true}
+		result := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&role)
+		if result.Error != nil {
+			return result.Error
+		}
+		created := result.RowsAffected > 0
+		if !created {
+			if err := db.Where("name = ?", builtin.Name).First(&role).Error; err != nil {
+				return err
+			}
+		}
+		for _, permission := range builtin.Permissions {
+			if !created && known[permission] {
+				continue // Possibly removed from the role on purpose
+			}
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.RolePermission{RoleID: role.ID, Permission: permission}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return db.Exec(`INSERT INTO user_roles (user_id, role_id)
+		SELECT users.id, roles.id FROM users JOIN roles ON roles.name = users.role
+		WHERE NOT EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = users.id)`).Error
+}
+
+// AssignRole gives the user the role with the given name. It fails with
+// ErrUnknownRole when there is no such role.
+func AssignRole(db *gorm.DB, userID uint, roleName string) error {
+	result := db.Exec(`INSERT INTO user_roles (user_id, role_id)
+		SELECT ?, id FROM roles WHERE name = ?
+		ON CONFLICT DO NOTHING`, userID, roleName)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	// Nothing inserted: either the user has the role already or it is missing
+	var count int64
+	if err := db.Model(&models.Role{}).Where("name = ?", roleName).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUnknownRole
+	}
+	return nil
+}
+
+// ReplaceRole takes the role named from away from the user and gives them
+// the role named to instead.
+func ReplaceRole(db *gorm.DB, userID uint, from, to string) error {
+	if err := db.Exec(`DELETE FROM user_roles
+		WHERE user_id = ? AND role_id IN (SELECT id FROM roles WHERE name = ?)`, userID, from).Error; err != nil {
+		return err
+	}
+	return AssignRole(db, userID, to)
+}
+
+// UserPermissions returns the union of the permissions of the user's roles.
+func UserPermissions(db *gorm.DB, userID uint) (map[string]bool, error) {
+	var names []string
+	err := db.Model(&models.RolePermission{}).
+		Distinct("permission").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permission", &names).Error
+	if err != nil {
+		return nil, err
+	}
+	permissions := make(map[string]bool, len(names))
+	for _, name := range names {
+		permissions[name] = true
+	}
+	return permissions, nil
+}
+
+// UsersWith selects the IDs of the users holding any of the permissions
+// through one of their roles, for use as a subquery.
+func UsersWith(db *gorm.DB, permissions ...string) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Table("user_roles").
+		Select("user_roles.user_id").
+		Joins("JOIN role_permissions ON role_permissions.role_id = user_roles.role_id").
+		Where("role_permissions.permission IN ?", permissions)
+}
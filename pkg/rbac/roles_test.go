@@ -0,0 +1,110 @@
+package rbac
+
+import (
+	"path/filepath"
+	"student-attendance-app/pkg/models"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Group{}, &models.Role{}, &models.RolePermission{}, &models.User{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestUserPermissions(t *testing.T) {
+	db := newTestDB(t)
+	user := models.User{Identifier: "student001", Password: "x", Name: "Student", Email: "student001@example.com", Role: RoleStudent}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	// Seed gives existing users the role named by their Role field
+	if err := Seed(db); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+
+	check := func(want map[string]bool) {
+		t.Helper()
+		got, err := UserPermissions(db, user.ID)
+		if err != nil {
+			t.Fatalf("UserPermissions failed: %v", err)
+		}
+		for permission, held := range want {
+			if got[permission] != held {
+				t.Errorf("permission %s held = %v, want %v", permission, got[permission], held)
+			}
+		}
+	}
+	check(map[string]bool{AttendanceSubmit: true, AttendanceReadOwn: true, CodesGenerate: false, UsersManage: false})
+
+	// Permissions of several roles add up
+	if err := AssignRole(db, user.ID, RoleTeacher); err != nil {
+		t.Fatalf("AssignRole failed: %v", err)
+	}
+	check(map[string]bool{AttendanceSubmit: true, CodesGenerate: true, UsersManage: false})
+
+	// Replacing a role keeps the others
+	if err := ReplaceRole(db, user.ID, RoleStudent, RoleAdmin); err != nil {
+		t.Fatalf("ReplaceRole failed: %v", err)
+	}
+	check(map[string]bool{AttendanceSubmit: false, CodesGenerate: true, UsersManage: true})
+}
+
+func TestSeedKeepsEditedRoles(t *testing.T) {
+	db := newTestDB(t)
+	if err := Seed(db); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	var teacher models.Role
+	if err := db.Where("name = ?", RoleTeacher).First(&teacher).Error; err != nil {
+		t.Fatalf("failed to load the teacher role: %v", err)
+	}
+	if err := db.Where("role_id = ? AND permission = ?", teacher.ID, CodesGenerate).Delete(&models.RolePermission{}).Error; err != nil {
+		t.Fatalf("failed to edit the teacher role: %v", err)
+	}
+
+	if err := Seed(db); err != nil {
+		t.Fatalf("second Seed failed: %v", err)
+	}
+	var count int64
+	db.Model(&models.RolePermission{}).Where("role_id = ? AND permission = ?", teacher.ID, CodesGenerate).Count(&count)
+	if count != 0 {
+		t.Errorf("second Seed granted %s to the edited teacher role again", CodesGenerate)
+	}
+}
+
+func TestSeedGrantsNewPermissions(t *testing.T) {
+	db := newTestDB(t)
+	if err := Seed(db); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	// A database seeded before excuses.review existed
+	if err := db.Where("permission = ?", ExcusesReview).Delete(&models.RolePermission{}).Error; err != nil {
+		t.Fatalf("failed to remove %s: %v", ExcusesReview, err)
+	}
+
+	if err := Seed(db); err != nil {
+		t.Fatalf("second Seed failed: %v", err)
+	}
+	var roles []string
+	db.Model(&models.RolePermission{}).
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("role_permissions.permission = ?", ExcusesReview).
+		Order("roles.name").
+		Pluck("roles.name", &roles)
+	if len(roles) != 2 || roles[0] != RoleAdmin || roles[1] != RoleCurator {
+		t.Errorf("%s granted to %v, want %s and %s", ExcusesReview, roles, RoleAdmin, RoleCurator)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"net/http"
 	"student-attendance-app/pkg/config"
 	"student-attendance-app/pkg/handlers"
+	"student-attendance-app/pkg/mailer"
 	"student-attendance-app/pkg/middleware"
+	"student-attendance-app/pkg/rbac"
+	"student-attendance-app/pkg/storage"
+	"student-attendance-app/pkg/throttle"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -70,41 +74,6 @@ const swaggerJSON = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/admin/users": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Получает список всех пользователей.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "admin"
-                ],
-                "summary": "Получить всех пользователей (Админ)",
-                "responses": {
-                    "200": {
-                        "description": "Список пользователей",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
             },
             "post": {
                 "security": [
@@ -112,7 +81,7 @@ const swaggerJSON = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Создает нового пользователя с указанными данными.",
+                "description": "Создает новую студенческую группу.",
                 "consumes": [
                     "application/json"
                 ],
@@ -122,23 +91,23 @@ const swaggerJSON = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Создать пользователя (Админ)",
+                "summary": "Создать группу (Админ)",
                 "parameters": [
                     {
-                        "description": "Объект пользователя",
-                        "name": "user",
+                        "description": "Данные группы",
+                        "name": "group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/handlers.GroupRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Созданный пользователь",
+                        "description": "Созданная группа",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Group"
                         }
                     },
                     "400": {
@@ -148,6 +117,13 @@ const swaggerJSON = `{
                             "additionalProperties": true
                         }
                     },
+                    "409": {
+                        "description": "Группа с таким названием уже существует",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -158,14 +134,14 @@ const swaggerJSON = `{
                 }
             }
         },
-        "/api/admin/users/{id}": {
+        "/api/admin/groups/{id}": {
             "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновляет данные существующего пользователя.",
+                "description": "Изменяет название существующей группы.",
                 "consumes": [
                     "application/json"
                 ],
@@ -175,30 +151,30 @@ const swaggerJSON = `{
                 "tags": [
                     "admin"
                 ],
-                "summary": "Обновить пользователя (Админ)",
+                "summary": "Переименовать группу (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Пользователя",
+                        "description": "ID Группы",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Объект пользователя",
-                        "name": "user",
+                        "description": "Данные группы",
+                        "name": "group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/handlers.GroupRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Обновленный пользователь",
+                        "description": "Обновленная группа",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Group"
                         }
                     },
                     "400": {
@@ -209,7 +185,14 @@ const swaggerJSON = `{
                         }
                     },
                     "404": {
-                        "description": "Пользователь не найден",
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Группа с таким названием уже существует",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -230,33 +213,46 @@ const swaggerJSON = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Удаляет пользователя по его ID.",
+                "description": "Удаляет группу. Если в группе есть пользователи или занятия, удаление отклоняется, пока не передан параметр cascade=true, который открепляет их от группы. Коды приглашения группы удаляются.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "Удалить пользователя (Админ)",
+                "summary": "Удалить группу (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Пользователя",
+                        "description": "ID Группы",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Открепить пользователей и занятия перед удалением",
+                        "name": "cascade",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Пользователь успешно удален",
+                        "description": "Группа успешно удалена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Пользователь не найден",
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Группа используется пользователями или занятиями",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -272,72 +268,62 @@ const swaggerJSON = `{
                 }
             }
         },
-        "/api/lessons": {
-            "get": {
+        "/api/admin/groups/{id}/curator": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Возвращает список занятий. Для студентов - занятия их группы. Для преподавателей/администраторов - все занятия.",
+                "description": "Назначает пользователя куратором группы и выдает ему роль curator, либо снимает куратора, если curator_id равен null. Куратор видит состав и посещаемость только своих групп.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "lessons"
+                    "admin"
+                ],
+                "summary": "Назначить куратора группы (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID Куратора",
+                        "name": "curator",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.CuratorRequest"
+                        }
+                    }
                 ],
-                "summary": "Получить занятия",
                 "responses": {
                     "200": {
-                        "description": "Список занятий",
+                        "description": "Обновленная группа",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Lesson"
-                            }
+                            "$ref": "#/definitions/models.Group"
                         }
                     },
-                    "404": {
-                        "description": "Пользователь не найден",
+                    "400": {
+                        "description": "Неверный запрос или пользователь не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "404": {
+                        "description": "Группа не найдена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/api/student/attendance": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Получает все записи о посещаемости для залогиненного студента.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "student"
-                ],
-                "summary": "Получить записи о посещаемости студента",
-                "responses": {
-                    "200": {
-                        "description": "Список записей о посещаемости",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Attendance"
-                            }
-                        }
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
@@ -347,14 +333,16 @@ const swaggerJSON = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/groups/{id}/invites": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Студент отправляет код посещаемости для определенного занятия.",
+                "description": "Создает код приглашения, по которому студенты регистрируются сразу в эту группу. Количество использований и срок действия можно ограничить.",
                 "consumes": [
                     "application/json"
                 ],
@@ -362,30 +350,43 @@ const swaggerJSON = `{
                     "application/json"
                 ],
                 "tags": [
-                    "student"
+                    "admin"
                 ],
-                "summary": "Отметить посещаемость",
+                "summary": "Создать код приглашения в группу (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для отметки посещаемости",
-                        "name": "attendance",
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ограничения кода",
+                        "name": "invite",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.SubmitAttendanceRequest"
+                            "$ref": "#/definitions/handlers.InviteRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Посещаемость успешно отмечена",
+                        "description": "Созданный код приглашения",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupInvite"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Неверный или просроченный код",
+                    "404": {
+                        "description": "Группа не найдена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -401,40 +402,47 @@ const swaggerJSON = `{
                 }
             }
         },
-        "/api/teacher/attendance/{lessonId}": {
+        "/api/admin/groups/{id}/lessons": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получает все записи о посещаемости для определенного занятия.",
+                "description": "Возвращает расписание занятий группы.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "teacher"
+                    "admin"
                 ],
-                "summary": "Получить посещаемость занятия",
+                "summary": "Получить занятия группы (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Занятия",
-                        "name": "lessonId",
+                        "description": "ID Группы",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список записей о посещаемости",
+                        "description": "Список занятий",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Attendance"
+                                "$ref": "#/definitions/models.Lesson"
                             }
                         }
                     },
+                    "404": {
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
@@ -445,39 +453,42 @@ const swaggerJSON = `{
                 }
             }
         },
-        "/api/teacher/lessons/{lessonId}/code": {
-            "post": {
+        "/api/admin/groups/{id}/members": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Генерирует новый 5-значный код для занятия, который истекает через 5 минут.",
+                "description": "Возвращает список пользователей, входящих в группу.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "teacher"
+                    "admin"
                 ],
-                "summary": "Сгенерировать код посещаемости",
+                "summary": "Получить участников группы (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Занятия",
-                        "name": "lessonId",
+                        "description": "ID Группы",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Сгенерированный код",
+                        "description": "Список пользователей",
                         "schema": {
-                            "$ref": "#/definitions/models.GeneratedCode"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Неверный запрос",
+                    "404": {
+                        "description": "Группа не найдена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -491,47 +502,42 @@ const swaggerJSON = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/guardian-links/{id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Деактивирует активный код для занятия.",
+                "description": "Удаляет привязку родителя к студенту. Роль guardian у аккаунта сохраняется.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "teacher"
+                    "admin"
                 ],
-                "summary": "Деактивировать код посещаемости",
+                "summary": "Отвязать родителя (Админ)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID Занятия",
-                        "name": "lessonId",
+                        "description": "ID Привязки",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Код успешно деактивирован",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Неверный запрос",
+                        "description": "Привязка удалена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Активный код не найден",
+                        "description": "Привязка не найдена",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -547,47 +553,40 @@ const swaggerJSON = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Аутентифицирует пользователя и возвращает JWT токен.",
-                "consumes": [
-                    "application/json"
+        "/api/admin/holidays/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Удаляет нерабочий день из семестра.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Вход пользователя",
+                "summary": "Удалить праздничный день (Админ)",
                 "parameters": [
                     {
-                        "description": "Учетные данные для входа",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.LoginRequest"
-                        }
+                        "type": "integer",
+                        "description": "ID Праздничного дня",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешный вход",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Неверный запрос",
+                        "description": "Праздничный день успешно удален",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Неверные учетные данные",
+                    "404": {
+                        "description": "Праздничный день не найден",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -603,37 +602,198 @@ const swaggerJSON = `{
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Создает нового пользователя-студента.",
-                "consumes": [
-                    "application/json"
+        "/api/admin/invites": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Возвращает коды приглашения, опционально только для одной группы.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Регистрация нового студента",
+                "summary": "Получить коды приглашения (Админ)",
                 "parameters": [
                     {
-                        "description": "Данные для регистрации пользователя",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "group_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список кодов приглашения",
                         "schema": {
-                            "$ref": "#/definitions/handlers.RegisterRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GroupInvite"
+                            }
                         }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/invites/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает код приглашения. Уже зарегистрированные по нему студенты остаются в группе.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Отозвать код приглашения (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Кода приглашения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Пользователь успешно зарегистрирован",
+                        "description": "Код отозван",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Код приглашения не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/lessons": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список занятий с фильтрацией по группе, преподавателю, дню недели и семестру.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить занятия (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "group_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Преподаватель (поиск по подстроке)",
+                        "name": "teacher",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Преподавателя",
+                        "name": "teacher_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "День недели",
+                        "name": "day",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "term_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список занятий",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Lesson"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает новое занятие в расписании.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Создать занятие (Админ)",
+                "parameters": [
+                    {
+                        "description": "Данные занятия",
+                        "name": "lesson",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LessonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданное занятие",
+                        "schema": {
+                            "$ref": "#/definitions/models.Lesson"
+                        }
                     },
                     "400": {
                         "description": "Неверный запрос",
@@ -643,7 +803,7 @@ const swaggerJSON = `{
                         }
                     },
                     "409": {
-                        "description": "Конфликт (идентификатор или email уже существует)",
+                        "description": "Занятие с таким названием, днем и временем уже существует в семестре",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -659,24 +819,122 @@ const swaggerJSON = `{
                 }
             }
         },
-        "/groups": {
-            "get": {
-                "description": "Возвращает список всех студенческих групп.",
+        "/api/admin/lessons/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет данные существующего занятия.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "public"
+                    "admin"
+                ],
+                "summary": "Обновить занятие (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные занятия",
+                        "name": "lesson",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LessonRequest"
+                        }
+                    }
                 ],
-                "summary": "Получить все группы",
                 "responses": {
                     "200": {
-                        "description": "Список групп",
+                        "description": "Обновленное занятие",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Group"
-                            }
+                            "$ref": "#/definitions/models.Lesson"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Занятие не найдено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Занятие с таким названием, днем и временем уже существует в семестре",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет занятие вместе с его кодами, сессиями и назначениями преподавателей. Занятия с записями о посещаемости удалить нельзя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить занятие (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Занятие успешно удалено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Занятие не найдено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "У занятия есть записи о посещаемости",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "500": {
@@ -688,78 +946,5594 @@ const swaggerJSON = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.LoginRequest": {
+        },
+        "/api/admin/lockouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает счетчики неудачных входов по логинам (identifier:...) и IP-адресам (ip:...), включая действующие блокировки (locked_until в будущем).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить блокировки входа (Админ)",
+                "responses": {
+                    "200": {
+                        "description": "Список счетчиков",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/throttle.Entry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/lockouts/{key}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сбрасывает счетчик неудачных входов и блокировку для ключа, например identifier:student001 или ip:10.0.0.5.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Снять блокировку входа (Админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ счетчика",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Блокировка снята",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все права, которые можно включить в роль.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить список прав (Админ)",
+                "responses": {
+                    "200": {
+                        "description": "Список прав",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rbac.Permission"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/registrations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает зарегистрировавшихся студентов с указанным статусом, по умолчанию ожидающих одобрения (pending). Старые заявки - первыми.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить очередь регистраций (Админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус: pending (по умолчанию) или rejected",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список пользователей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный статус",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/registrations/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Активирует аккаунт студента, ожидающего одобрения (или ранее отклоненного), и сообщает ему об этом по email.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Одобрить регистрацию (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Одобренный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь уже активен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/registrations/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отклоняет регистрацию студента, ожидающего одобрения. Отклоненный пользователь не может войти.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Отклонить регистрацию (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отклоненный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь уже активен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает все роли с их правами.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить роли (Админ)",
+                "responses": {
+                    "200": {
+                        "description": "Список ролей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.RoleResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает роль с указанным набором прав.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Создать роль (Админ)",
+                "parameters": [
+                    {
+                        "description": "Данные роли",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданная роль",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или неизвестное право",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Роль с таким названием уже существует",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/roles/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет описание и набор прав роли. Изменения применяются ко всем пользователям роли сразу. Встроенные роли нельзя переименовать, а роль admin всегда сохраняет право roles.manage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Обновить роль (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные роли",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленная роль",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RoleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или неизвестное право",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Роль не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Роль с таким названием уже существует",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет роль, которая не назначена ни одному пользователю. Встроенные роли удалить нельзя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить роль (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Роли",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Роль удалена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Встроенную роль нельзя удалить",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Роль не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Роль назначена пользователям",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/terms": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список всех семестров с праздничными днями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить семестры (Админ)",
+                "responses": {
+                    "200": {
+                        "description": "Список семестров",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Term"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает новый семестр. Семестры не могут пересекаться по датам.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Создать семестр (Админ)",
+                "parameters": [
+                    {
+                        "description": "Данные семестра",
+                        "name": "term",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TermRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданный семестр",
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Семестр с таким названием уже существует или пересекается с другим",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/terms/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Изменяет название и даты семестра.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Обновить семестр (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные семестра",
+                        "name": "term",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.TermRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный семестр",
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Семестр с таким названием уже существует или пересекается с другим",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет семестр вместе с его праздничными днями. Семестр, к которому привязаны занятия, удалить нельзя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить семестр (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Семестр успешно удален",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "К семестру привязаны занятия",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/terms/{id}/holidays": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Добавляет нерабочий день в семестр. В этот день занятия семестра не проводятся.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Добавить праздничный день (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные праздничного дня",
+                        "name": "holiday",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.HolidayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданный праздничный день",
+                        "schema": {
+                            "$ref": "#/definitions/models.Holiday"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Этот день уже отмечен как праздничный",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получает список всех пользователей.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить всех пользователей (Админ)",
+                "responses": {
+                    "200": {
+                        "description": "Список пользователей",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает нового пользователя с указанными данными и назначает ему встроенную роль, совпадающую с полем role. Email такого пользователя считается подтвержденным.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Создать пользователя (Админ)",
+                "parameters": [
+                    {
+                        "description": "Объект пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновляет данные существующего пользователя. При смене поля role встроенная роль пользователя заменяется новой, остальные роли сохраняются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Обновить пользователя (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Объект пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет пользователя по его ID вместе с его объяснительными и приложенными к ним файлами.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить пользователя (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь успешно удален",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/guardians": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает родителей, привязанных к студенту.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить родителей студента (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Студента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список привязок",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GuardianLink"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Пользователь не является студентом",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Привязывает существующий аккаунт родителем студента и выдает ему роль guardian.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Привязать родителя к студенту (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Студента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID Родителя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GuardianLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Родитель привязан",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос, пользователь не является студентом или родитель не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/guardians/invites": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отправляет на указанный email приглашение стать родителем студента.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Пригласить родителя студента (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Студента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Email родителя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GuardianInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отправленное приглашение",
+                        "schema": {
+                            "$ref": "#/definitions/models.GuardianInvite"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или пользователь не является студентом",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает роли, назначенные пользователю.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить роли пользователя (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Роли пользователя",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.RoleResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет набор ролей пользователя. Права пользователя складываются из прав всех его ролей и меняются сразу, без повторного входа. Нельзя лишить себя права roles.manage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Назначить роли пользователю (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID ролей",
+                        "name": "roles",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.UserRolesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Роли пользователя",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.RoleResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или неизвестная роль",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/sessions": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Завершает все сессии пользователя на всех устройствах. Выданные токены доступа перестают приниматься сразу, для продолжения работы нужно войти заново.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Завершить все сессии пользователя (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сессии завершены (revoked - количество)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/users/{id}/verify-email": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отмечает email пользователя как подтвержденный без письма, например если письмо не доходит.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Подтвердить email пользователя (Админ)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь с подтвержденным email",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/excuses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает объяснительные студентов курируемых групп с указанным статусом, по умолчанию ожидающие рассмотрения (pending), старые - первыми. Пользователям с правом attendance.read.all возвращаются объяснительные всех групп.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Получить объяснительные студентов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Статус: pending (по умолчанию), approved или rejected",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "group_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список объяснительных",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExcuseRequest"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный статус",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/excuses/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Куратор группы или администратор одобряет объяснительную (в том числе ранее отклоненную). Пропуски студента в указанном периоде начинают считаться пропусками по уважительной причине (excused) во всех отчетах о посещаемости. Студенту отправляется email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Одобрить объяснительную",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Объяснительной",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "review",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ExcuseReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Одобренная объяснительная",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExcuseRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Объяснительная не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/excuses/{id}/file": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает файл, приложенный к объяснительной студента курируемой группы.",
+                "produces": [
+                    "application/pdf",
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Скачать документ объяснительной",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Объяснительной",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Документ",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Объяснительная или файл не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/excuses/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Куратор группы или администратор отклоняет объяснительную, в том числе ранее одобренную, после чего пропуски снова считаются неуважительными. Студенту отправляется email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Отклонить объяснительную",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Объяснительной",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "review",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ExcuseReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отклоненная объяснительная",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExcuseRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Объяснительная не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/groups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает группы, куратором которых назначен залогиненный пользователь. Пользователям с правом attendance.read.all возвращаются все группы.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Получить курируемые группы",
+                "responses": {
+                    "200": {
+                        "description": "Список групп",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Group"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/groups/{id}/invites": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает коды приглашения в группу, которую курирует пользователь.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Получить коды приглашения в курируемую группу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список кодов приглашения",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GroupInvite"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создает код приглашения в группу, которую курирует пользователь. Студенты регистрируются по нему сразу в эту группу. Количество использований и срок действия можно ограничить.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Создать код приглашения в курируемую группу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ограничения кода",
+                        "name": "invite",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.InviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданный код приглашения",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupInvite"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/groups/{id}/journal": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает студентов группы, проведенные занятия (сессии) ее расписания и отметки о присутствии, опционально только за указанный семестр. Доступно только куратору группы и пользователям с правом attendance.read.all.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Получить журнал посещаемости курируемой группы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "term_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Журнал посещаемости",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.JournalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена или не курируется пользователем",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/groups/{id}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает для каждого студента группы число проведенных занятий, посещенных и пропущенных, и процент посещаемости, опционально только за указанный семестр. Доступно только куратору группы и пользователям с правом attendance.read.all.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Получить статистику пропусков курируемой группы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "term_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статистика по студентам",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.AttendanceStats"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена или не курируется пользователем",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/groups/{id}/students": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает студентов группы. Доступно только куратору группы и пользователям с правом attendance.read.all.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Получить состав курируемой группы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список студентов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Группа не найдена или не курируется пользователем",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/curator/invites/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отзывает код приглашения в группу, которую курирует пользователь. Уже зарегистрированные по нему студенты остаются в группе.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "curator"
+                ],
+                "summary": "Отозвать код приглашения в курируемую группу",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Кода приглашения",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Код отозван",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Код приглашения не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/guardian/invites/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Привязывает студента из приглашения к аккаунту залогиненного пользователя и выдает ему роль guardian. Email аккаунта должен совпадать с адресом, на который отправлено приглашение.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guardian"
+                ],
+                "summary": "Принять приглашение родителя",
+                "parameters": [
+                    {
+                        "description": "Токен из письма",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GuardianInviteTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Студент привязан",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный или просроченный токен (code: invalid_token)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Приглашение отправлено на другой email (code: invite_email_mismatch)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/guardian/students": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает студентов, родителем которых является залогиненный пользователь.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guardian"
+                ],
+                "summary": "Получить привязанных студентов",
+                "responses": {
+                    "200": {
+                        "description": "Список студентов",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/guardian/students/{id}/attendance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает записи о посещаемости студента, как GET /api/student/attendance, опционально только за указанный семестр. Доступно только родителям студента.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guardian"
+                ],
+                "summary": "Получить посещаемость привязанного студента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Студента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "term_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список записей о посещаемости",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Attendance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Студент не найден или не привязан",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/guardian/students/{id}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает число проведенных занятий группы студента, посещенных и пропущенных, и процент посещаемости, опционально только за указанный семестр. Доступно только родителям студента.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "guardian"
+                ],
+                "summary": "Получить статистику посещаемости привязанного студента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Студента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "term_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Статистика посещаемости",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AttendanceStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Студент не найден или не привязан",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/lessons": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает расписание на текущую неделю (или неделю, содержащую date): занятия текущего семестра и занятия без семестра, с учетом числителя/знаменателя. Для пользователей без прав lessons.teach и lessons.manage (студентов) - занятия их группы, для остальных - все занятия.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Получить занятия",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Любой день нужной недели (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список занятий",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Lesson"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/attendance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получает все записи о посещаемости для залогиненного студента, опционально только за указанный семестр. Каждая запись содержит статус (present, late, absent или excused) и число минут опоздания.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Получить записи о посещаемости студента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "term_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список записей о посещаемости",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Attendance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Семестр не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Студент отправляет код посещаемости для определенного занятия. Меняющиеся коды проверяются по текущему временному окну. После 3 неверных кодов в одной сессии студент блокируется на время, которое удваивается с каждой следующей ошибкой (до 15 минут).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Отметить посещаемость",
+                "parameters": [
+                    {
+                        "description": "Данные для отметки посещаемости",
+                        "name": "attendance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.SubmitAttendanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Посещаемость успешно отмечена или уже была отмечена ранее (already_marked), со статусом present или late и minutes_late",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный или просроченный код",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Студент не состоит в группе занятия (code: not_in_lesson_group)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов (code: too_many_attempts, retry_after в секундах)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/attendance/scan": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Студент отправляет содержимое отсканированного QR-кода (ссылку или токен) вместо ID занятия и кода. Токен подписан сервером и действует, пока действует показанный код.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Отметить посещаемость по QR-коду",
+                "parameters": [
+                    {
+                        "description": "Содержимое QR-кода",
+                        "name": "scan",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ScanAttendanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Посещаемость успешно отмечена или уже была отмечена ранее (already_marked)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверная или просроченная ссылка (code: invalid_checkin) или неверный код",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Студент не состоит в группе занятия (code: not_in_lesson_group)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Пользователь не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов (code: too_many_attempts)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/excuses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает объяснительные (справки) залогиненного студента со статусом рассмотрения, начиная с последних.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Получить мои объяснительные",
+                "responses": {
+                    "200": {
+                        "description": "Список объяснительных",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ExcuseRequest"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Студент подает объяснительную за период с приложенным документом (PDF или JPEG), например медицинской справкой. После одобрения куратором или администратором пропуски в этом периоде считаются пропусками по уважительной причине.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Подать объяснительную",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Первый день (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Последний день (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Причина",
+                        "name": "reason",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Документ (PDF или JPEG)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поданная объяснительная",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExcuseRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или тип файла",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Файл слишком большой",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/excuses/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Студент отзывает объяснительную, которая еще не рассмотрена. Приложенный файл удаляется.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Отозвать объяснительную",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Объяснительной",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Объяснительная отозвана",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Объяснительная не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Объяснительная уже рассмотрена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/excuses/{id}/file": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает файл, приложенный к объяснительной залогиненного студента.",
+                "produces": [
+                    "application/pdf",
+                    "image/jpeg"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Скачать документ моей объяснительной",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Объяснительной",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Документ",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Объяснительная или файл не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/guardians": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает родителей, которым открыт доступ к посещаемости залогиненного студента.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Получить моих родителей",
+                "responses": {
+                    "200": {
+                        "description": "Список привязок",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.GuardianLink"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/guardians/invites": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отправляет на указанный email приглашение, по которому родитель создает аккаунт или привязывает студента к существующему и получает доступ к его посещаемости только для чтения.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Пригласить родителя",
+                "parameters": [
+                    {
+                        "description": "Email родителя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GuardianInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отправленное приглашение",
+                        "schema": {
+                            "$ref": "#/definitions/models.GuardianInvite"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/student/guardians/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удаляет привязку родителя к залогиненному студенту.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "student"
+                ],
+                "summary": "Отключить доступ родителя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Привязки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Привязка удалена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Привязка не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/attendance/{lessonId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получает записи о посещаемости для одной сессии занятия. Сессия выбирается по session_id или дате, по умолчанию - последняя. Каждая запись содержит статус (present, late, absent или excused) и число минут опоздания.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить посещаемость занятия",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Сессии",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата сессии (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список записей о посещаемости",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Attendance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает занятия текущего семестра, в которых залогиненный преподаватель назначен ведущим или ассистентом, а также занятия, права на которые ему делегированы. Пользователям с правом lessons.manage возвращаются все занятия семестра.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить мои занятия",
+                "responses": {
+                    "200": {
+                        "description": "Список занятий",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Lesson"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/attempts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает студентов, вводивших неверный код в сессии занятия, с количеством ошибок и временем блокировки. Сессия выбирается по session_id или дате, по умолчанию - последняя.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить неудачные попытки ввода кода",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Сессии",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата сессии (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список попыток, больше всего ошибок - первыми",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CodeAttempt"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/attendance/changes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает ручные отметки, исправления и снятия отметок на занятии с причиной, автором и временем, начиная с последних. С session_id или date - только для одной сессии.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить журнал изменений посещаемости",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Сессии",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата сессии (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Журнал изменений",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AttendanceChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Сессия не найдена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/attendance/{studentId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Преподаватель отмечает студента на сессии занятия или меняет статус существующей отметки (present, late или excused), например если у студента разрядился телефон. Причина обязательна; изменение записывается в журнал изменений вместе с автором и временем. Такие отметки получают source = manual. Сессия выбирается по session_id или дате, по умолчанию - последняя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Отметить студента вручную",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Студента",
+                        "name": "studentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Сессии",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата сессии (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Статус и причина",
+                        "name": "mark",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ManualAttendanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сохраненная отметка",
+                        "schema": {
+                            "$ref": "#/definitions/models.Attendance"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос, сессия еще не началась или студент не из группы занятия",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Занятие, сессия или студент не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Преподаватель удаляет отметку студента на сессии занятия, после чего студент считается отсутствующим. Причина обязательна и записывается в журнал изменений. Сессия выбирается по session_id или дате, по умолчанию - последняя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Снять отметку студента",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Студента",
+                        "name": "studentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Сессии",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата сессии (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Причина",
+                        "name": "reason",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AttendanceReasonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Отметка снята",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или сессия еще не началась",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Занятие, сессия, студент или отметка не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/code": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает активный код занятия для показа студентам. Для меняющегося кода возвращает значение текущего временного окна и время следующей смены (rotates_at).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить текущий код посещаемости",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Активный код",
+                        "schema": {
+                            "$ref": "#/definitions/models.GeneratedCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Активный код не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Генерирует новый код для сегодняшней сессии занятия. Длина, алфавит и срок действия кода берутся из настроек занятия или из конфигурации сервера (по умолчанию 5 цифр на 15 минут). Постоянный код не совпадает ни с одним другим активным кодом. Меняющийся код (mode=rotating) пересчитывается каждые 10-30 секунд, его текущее значение возвращает GET на этот же адрес.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Сгенерировать код посещаемости",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Параметры кода",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.GenerateCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сгенерированный код",
+                        "schema": {
+                            "$ref": "#/definitions/models.GeneratedCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или занятие не проводится сегодня",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Занятие не найдено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Деактивирует активный код для занятия.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Деактивировать код посещаемости",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Код успешно деактивирован",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Активный код не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/code/qr": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает активный код занятия в виде QR-кода (PNG или SVG) с подписанной ссылкой для отметки. Для меняющегося кода изображение нужно запрашивать заново после rotates_at (заголовок X-Code-Rotates-At).",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить QR-код для отметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат изображения: png (по умолчанию) или svg",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер PNG в пикселях, от 128 до 1024 (по умолчанию 512)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "QR-код",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Активный код не найден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/delegations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список преподавателей, которым переданы права на занятие.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить делегирования занятия",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список делегирований",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LessonDelegation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ведущий преподаватель передает другому преподавателю (ассистенту или замещающему) право генерировать коды и просматривать посещаемость занятия, опционально до указанного времени.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Делегировать права на занятие",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные делегирования",
+                        "name": "delegation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.DelegationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданное делегирование",
+                        "schema": {
+                            "$ref": "#/definitions/models.LessonDelegation"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Пользователь не является ведущим преподавателем занятия",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/delegations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ведущий преподаватель отзывает ранее переданные права на занятие.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Отозвать делегирование",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Делегирования",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Делегирование отозвано",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Пользователь не является ведущим преподавателем занятия",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Делегирование не найдено",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/roster": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает всех студентов группы занятия со статусом в одной сессии (present, late, absent или excused), итоги и проценты по статусам. Студенты без отметки считаются отсутствующими; source показывает, отмечен ли студент по коду (code) или преподавателем вручную (manual). Сессия выбирается по session_id или дате, по умолчанию - последняя. С format=csv возвращает файл для печати.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить полный список группы на занятии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Сессии",
+                        "name": "session_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата сессии (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json (по умолчанию) или csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список группы",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RosterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Занятие или сессия не найдены",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/teacher/lessons/{lessonId}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список датированных занятий (сессий), созданных по недельному расписанию занятия.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teacher"
+                ],
+                "summary": "Получить проведенные занятия",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID Занятия",
+                        "name": "lessonId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID Семестра",
+                        "name": "term_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список сессий",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LessonSession"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Нет доступа к занятию",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/terms/current": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает семестр, который идет сегодня, вместе с его праздничными днями.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lessons"
+                ],
+                "summary": "Получить текущий семестр",
+                "responses": {
+                    "200": {
+                        "description": "Текущий семестр",
+                        "schema": {
+                            "$ref": "#/definitions/models.Term"
+                        }
+                    },
+                    "404": {
+                        "description": "Сейчас нет активного семестра",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Отправляет на email пользователя одноразовую ссылку для сброса пароля, действующую 1 час. Ответ одинаков для существующих и несуществующих пользователей.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Запросить сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Логин или email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Запрос принят",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/guardian-invite/accept": {
+            "post": {
+                "description": "Создает аккаунт родителя с email из приглашения и привязывает к нему студента. Email считается подтвержденным. Если аккаунт с этим email уже существует, нужно войти в него и принять приглашение через /api/guardian/invites/accept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Создать аккаунт родителя по приглашению",
+                "parameters": [
+                    {
+                        "description": "Токен из письма и данные аккаунта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.AcceptGuardianInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Аккаунт создан",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный или просроченный токен (code: invalid_token)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Аккаунт с таким email уже существует (code: account_exists) или логин занят",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Аутентифицирует пользователя и возвращает короткоживущий JWT токен (token, срок в секундах в expires_in) и одноразовый refresh_token для его обновления через /auth/refresh. После 5 неудачных попыток для логина или 20 с одного IP-адреса вход временно блокируется, время блокировки удваивается с каждой следующей ошибкой (до 15 минут).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Вход пользователя",
+                "parameters": [
+                    {
+                        "description": "Учетные данные для входа",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешный вход",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Неверные учетные данные",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Email не подтвержден или аккаунт не одобрен (code: email_not_verified, account_pending, account_rejected)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неудачных попыток (code: login_locked, retry_after в секундах)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Завершает сессию, к которой относится refresh_token. Токены доступа этой сессии перестают приниматься сразу.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Выход",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Сессия завершена",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Обменивает refresh_token на новую пару токенов. Каждый refresh_token действует один раз; повторное использование уже обмененного токена завершает сессию (code: refresh_token_reused).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Обновить токен доступа",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Новые token, refresh_token и expires_in",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Недействительный токен (code: invalid_refresh_token или refresh_token_reused)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Создает нового пользователя-студента и отправляет на его email ссылку для подтверждения. Войти можно только после подтверждения. В зависимости от политики регистрации (GET /auth/registration-policy) группа выбирается студентом (open), определяется кодом приглашения (invite) или аккаунт ждет одобрения администратора (approval, status: pending).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация нового студента",
+                "parameters": [
+                    {
+                        "description": "Данные для регистрации пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пользователь успешно зарегистрирован",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос или код приглашения (code: invite_required, invalid_invite)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Конфликт (идентификатор или email уже существует)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/registration-policy": {
+            "get": {
+                "description": "Возвращает политику самостоятельной регистрации студентов: open (группа выбирается из списка), invite (нужен код приглашения) или approval (аккаунт одобряет администратор).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Получить политику регистрации",
+                "responses": {
+                    "200": {
+                        "description": "Политика регистрации (policy)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/resend-verification": {
+            "post": {
+                "description": "Отправляет новую ссылку для подтверждения email, если адрес пользователя еще не подтвержден. Ответ одинаков для любых логинов.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Повторно отправить письмо для подтверждения email",
+                "parameters": [
+                    {
+                        "description": "Логин или email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ResendVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Запрос принят",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Задает новый пароль по одноразовому токену из письма. Все сессии пользователя завершаются, блокировка входа по логину снимается.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Сбросить пароль",
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Пароль изменен",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный или просроченный токен (code: invalid_token)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Подтверждает email пользователя по одноразовому токену из письма, отправленного при регистрации. После этого пользователь может войти.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подтвердить email",
+                "parameters": [
+                    {
+                        "description": "Токен из письма",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email подтвержден",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный или просроченный токен (code: invalid_token)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "description": "Возвращает список всех студенческих групп.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Получить все группы",
+                "responses": {
+                    "200": {
+                        "description": "Список групп",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Group"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.AcceptGuardianInviteRequest": {
+            "type": "object",
+            "required": [
+                "identifier",
+                "name",
+                "password",
+                "token"
+            ],
+            "properties": {
+                "identifier": {
+                    "type": "string",
+                    "example": "parent001"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Parent Name"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "securepassword"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"
+                }
+            }
+        },
+        "handlers.AttendanceReasonRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Отмечен по ошибке"
+                }
+            }
+        },
+        "handlers.AttendanceStats": {
+            "type": "object",
+            "properties": {
+                "attended": {
+                    "description": "Present or late",
+                    "type": "integer"
+                },
+                "excused": {
+                    "type": "integer"
+                },
+                "late": {
+                    "type": "integer"
+                },
+                "missed": {
+                    "description": "Neither attended nor excused",
+                    "type": "integer"
+                },
+                "rate": {
+                    "description": "Percentage of sessions attended",
+                    "type": "number",
+                    "example": 87.5
+                },
+                "sessions": {
+                    "description": "Sessions the student was expected at",
+                    "type": "integer"
+                },
+                "student": {
+                    "$ref": "#/definitions/models.User"
+                }
+            }
+        },
+        "handlers.CuratorRequest": {
+            "type": "object",
+            "properties": {
+                "curator_id": {
+                    "description": "Removes the curator when null",
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "handlers.DelegationRequest": {
+            "type": "object",
+            "required": [
+                "teacher_id"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-10-01T00:00:00Z"
+                },
+                "teacher_id": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "handlers.ExcuseReviewRequest": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Справка принята"
+                }
+            }
+        },
+        "handlers.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "login"
+            ],
+            "properties": {
+                "login": {
+                    "description": "Identifier or email",
+                    "type": "string",
+                    "example": "student001"
+                }
+            }
+        },
+        "handlers.GenerateCodeRequest": {
+            "type": "object",
+            "required": [
+                "lesson_id"
+            ],
+            "properties": {
+                "lesson_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "description": "'static' or 'rotating', defaults to the server setting",
+                    "type": "string",
+                    "example": "rotating"
+                }
+            }
+        },
+        "handlers.GroupRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Group D"
+                }
+            }
+        },
+        "handlers.GuardianInviteRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "parent@example.com"
+                }
+            }
+        },
+        "handlers.GuardianInviteTokenRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"
+                }
+            }
+        },
+        "handlers.GuardianLinkRequest": {
+            "type": "object",
+            "required": [
+                "guardian_id"
+            ],
+            "properties": {
+                "guardian_id": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "handlers.HolidayRequest": {
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-11-04"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "День народного единства"
+                }
+            }
+        },
+        "handlers.InviteRequest": {
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "description": "Never expires when omitted",
+                    "type": "integer",
+                    "example": 72
+                },
+                "max_uses": {
+                    "description": "Unlimited when omitted",
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "handlers.JournalMark": {
+            "type": "object",
+            "properties": {
+                "minutes_late": {
+                    "type": "integer"
+                },
+                "session_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "'code', 'manual' or 'excuse'",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "integer"
+                },
+                "submitted_at": {
+                    "description": "Nil for excused absences",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.JournalResponse": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "$ref": "#/definitions/models.Group"
+                },
+                "marks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.JournalMark"
+                    }
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LessonSession"
+                    }
+                },
+                "students": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                }
+            }
+        },
+        "handlers.LessonRequest": {
+            "type": "object",
+            "required": [
+                "day",
+                "name",
+                "time"
+            ],
+            "properties": {
+                "assistant_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        5
+                    ]
+                },
+                "code_alphabet": {
+                    "type": "string",
+                    "example": "alphanumeric"
+                },
+                "code_length": {
+                    "description": "Overrides of the server-wide attendance code settings, null to use them",
+                    "type": "integer",
+                    "example": 6
+                },
+                "code_ttl_seconds": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "day": {
+                    "type": "string",
+                    "example": "Понедельник"
+                },
+                "group_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Алгебра"
+                },
+                "room": {
+                    "type": "string",
+                    "example": "101"
+                },
+                "teacher": {
+                    "description": "Defaults to the names of teacher_ids; kept on update without teacher or teacher_ids",
+                    "type": "string",
+                    "example": "Анна Владимировна"
+                },
+                "teacher_ids": {
+                    "description": "Teacher accounts leading and assisting the lesson. When both are\nomitted the current assignments are kept.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2
+                    ]
+                },
+                "term_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "time": {
+                    "type": "string",
+                    "example": "09:00-10:30"
+                },
+                "week_parity": {
+                    "description": "'every' (default), 'odd' or 'even'",
+                    "type": "string",
+                    "example": "every"
+                }
+            }
+        },
+        "handlers.LoginRequest": {
+            "type": "object",
+            "required": [
+                "identifier",
+                "password"
+            ],
+            "properties": {
+                "identifier": {
+                    "type": "string",
+                    "example": "student1"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "password123"
+                }
+            }
+        },
+        "handlers.ManualAttendanceRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "status"
+            ],
+            "properties": {
+                "minutes_late": {
+                    "description": "Only for late marks",
+                    "type": "integer",
+                    "example": 0
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Разрядился телефон"
+                },
+                "status": {
+                    "description": "'present', 'late' or 'excused'",
+                    "type": "string",
+                    "example": "present"
+                }
+            }
+        },
+        "handlers.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"
+                }
+            }
+        },
+        "handlers.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "identifier",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "new@example.com"
+                },
+                "group_id": {
+                    "description": "Required unless registering with an invite code",
+                    "type": "integer",
+                    "example": 1
+                },
+                "identifier": {
+                    "type": "string",
+                    "example": "newstudent"
+                },
+                "invite_code": {
+                    "description": "Required with the invite registration policy",
+                    "type": "string",
+                    "example": "K7M2QX9P4A"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "New Student"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "securepassword"
+                }
+            }
+        },
+        "handlers.ResendVerificationRequest": {
+            "type": "object",
+            "required": [
+                "login"
+            ],
+            "properties": {
+                "login": {
+                    "description": "Identifier or email",
+                    "type": "string",
+                    "example": "newstudent"
+                }
+            }
+        },
+        "handlers.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "newsecurepassword"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"
+                }
+            }
+        },
+        "handlers.RoleRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Куратор группы"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "curator"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "attendance.read.group"
+                    ]
+                }
+            }
+        },
+        "handlers.RoleResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "system": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RosterCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "percent": {
+                    "type": "number",
+                    "example": 87.5
+                }
+            }
+        },
+        "handlers.RosterEntry": {
+            "type": "object",
+            "properties": {
+                "marked_by": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "minutes_late": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "'code', 'manual' or 'excuse', empty for absent students",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "'present', 'late', 'absent' or 'excused'",
+                    "type": "string"
+                },
+                "student": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "submitted_at": {
+                    "description": "Nil for students without a mark",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.RosterResponse": {
+            "type": "object",
+            "properties": {
+                "lesson": {
+                    "$ref": "#/definitions/models.Lesson"
+                },
+                "session": {
+                    "$ref": "#/definitions/models.LessonSession"
+                },
+                "students": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.RosterEntry"
+                    }
+                },
+                "totals": {
+                    "$ref": "#/definitions/handlers.RosterTotals"
+                }
+            }
+        },
+        "handlers.RosterTotals": {
+            "type": "object",
+            "properties": {
+                "absent": {
+                    "$ref": "#/definitions/handlers.RosterCount"
+                },
+                "excused": {
+                    "$ref": "#/definitions/handlers.RosterCount"
+                },
+                "expected": {
+                    "type": "integer"
+                },
+                "late": {
+                    "$ref": "#/definitions/handlers.RosterCount"
+                },
+                "present": {
+                    "$ref": "#/definitions/handlers.RosterCount"
+                }
+            }
+        },
+        "handlers.ScanAttendanceRequest": {
+            "type": "object",
+            "required": [
+                "payload"
+            ],
+            "properties": {
+                "payload": {
+                    "type": "string",
+                    "example": "http://localhost:5173/checkin?t=eyJsIjoxfQ.c2ln"
+                }
+            }
+        },
+        "handlers.SubmitAttendanceRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "lesson_id"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "12345"
+                },
+                "lesson_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "handlers.TermRequest": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "name",
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "type": "string",
+                    "example": "2025-12-28"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Осенний семестр 2025"
+                },
+                "start_date": {
+                    "type": "string",
+                    "example": "2025-09-01"
+                }
+            }
+        },
+        "handlers.UserRolesRequest": {
+            "type": "object",
+            "required": [
+                "role_ids"
+            ],
+            "properties": {
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "handlers.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "mXjW1n2o3p4q5r6s7t8u9v0wxyzABCDEFGHIJKLMNOP"
+                }
+            }
+        },
+        "models.Attendance": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "lesson": {
+                    "$ref": "#/definitions/models.Lesson"
+                },
+                "lesson_id": {
+                    "type": "integer"
+                },
+                "marked_by": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "marked_by_id": {
+                    "description": "Teacher who last set the mark manually",
+                    "type": "integer"
+                },
+                "minutes_late": {
+                    "description": "Minutes after the scheduled start, for late marks",
+                    "type": "integer"
+                },
+                "session": {
+                    "$ref": "#/definitions/models.LessonSession"
+                },
+                "session_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "'code' or 'manual'",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "'present', 'late', 'absent' or 'excused'",
+                    "type": "string"
+                },
+                "student": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "student_id": {
+                    "type": "integer"
+                },
+                "submitted_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AttendanceChange": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string"
+                },
+                "changed_by": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "changed_by_id": {
+                    "description": "Nil once the teacher's account is deleted",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lesson_id": {
+                    "type": "integer"
+                },
+                "new_status": {
+                    "description": "Empty when the mark was removed",
+                    "type": "string"
+                },
+                "old_status": {
+                    "description": "Empty when the student had no mark",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "integer"
+                },
+                "student": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "student_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CodeAttempt": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_failed_at": {
+                    "type": "string"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "integer"
+                },
+                "student": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "student_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ExcuseRequest": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "description": "'application/pdf' or 'image/jpeg'",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "file_size": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "review_comment": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "reviewed_by_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "'pending', 'approved' or 'rejected'",
+                    "type": "string"
+                },
+                "student": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "student_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GeneratedCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "lesson": {
+                    "$ref": "#/definitions/models.Lesson"
+                },
+                "lesson_id": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "description": "'static' or 'rotating'",
+                    "type": "string"
+                },
+                "rotates_at": {
+                    "description": "When the current rotating value changes",
+                    "type": "string"
+                },
+                "session": {
+                    "$ref": "#/definitions/models.LessonSession"
+                },
+                "session_id": {
+                    "type": "integer"
+                },
+                "step_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Group": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "curator": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "curator_id": {
+                    "description": "CuratorID is the staff member (куратор) who follows the group's\nattendance. No foreign key, since users already reference groups.",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupInvite": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "group": {
+                    "$ref": "#/definitions/models.Group"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GuardianInvite": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "student_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GuardianLink": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "guardian": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "guardian_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "student": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "student_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Holiday": {
             "type": "object",
-            "required": [
-                "identifier",
-                "password"
-            ],
             "properties": {
-                "identifier": {
-                    "type": "string",
-                    "example": "student1"
+                "created_at": {
+                    "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "password123"
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "term_id": {
+                    "type": "integer"
                 }
             }
         },
-        "handlers.RegisterRequest": {
+        "models.Lesson": {
             "type": "object",
-            "required": [
-                "email",
-                "group_id",
-                "identifier",
-                "name",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "new@example.com"
+                "code_alphabet": {
+                    "description": "'digits' or 'alphanumeric'",
+                    "type": "string"
+                },
+                "code_length": {
+                    "description": "Overrides of the attendance code defaults from config.Config",
+                    "type": "integer"
+                },
+                "code_ttl_seconds": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "day": {
+                    "type": "string"
+                },
+                "group": {
+                    "$ref": "#/definitions/models.Group"
                 },
                 "group_id": {
-                    "type": "integer",
-                    "example": 1
+                    "type": "integer"
                 },
-                "identifier": {
-                    "type": "string",
-                    "example": "newstudent"
+                "id": {
+                    "type": "integer"
                 },
                 "name": {
-                    "type": "string",
-                    "example": "New Student"
+                    "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "example": "securepassword"
+                "room": {
+                    "type": "string"
+                },
+                "teacher": {
+                    "description": "Display name of the lead teachers, see Teachers",
+                    "type": "string"
+                },
+                "teachers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LessonTeacher"
+                    }
+                },
+                "term": {
+                    "$ref": "#/definitions/models.Term"
+                },
+                "term_id": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "week_parity": {
+                    "description": "'every', 'odd' or 'even'",
+                    "type": "string"
                 }
             }
         },
-        "handlers.SubmitAttendanceRequest": {
+        "models.LessonDelegation": {
             "type": "object",
-            "required": [
-                "code",
-                "lesson_id"
-            ],
             "properties": {
-                "code": {
-                    "type": "string",
-                    "example": "12345"
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Never expires when nil",
+                    "type": "string"
+                },
+                "granted_by_id": {
+                    "type": "integer"
+                },
+                "grantee": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "grantee_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
                 },
                 "lesson_id": {
-                    "type": "integer",
-                    "example": 1
+                    "type": "integer"
                 }
             }
         },
-        "models.Attendance": {
+        "models.LessonSession": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -769,74 +6543,72 @@ const swaggerJSON = `{
                 "lesson_id": {
                     "type": "integer"
                 },
-                "student": {
-                    "$ref": "#/definitions/models.User"
-                },
-                "student_id": {
-                    "type": "integer"
-                },
-                "submitted_at": {
+                "starts_at": {
                     "type": "string"
                 }
             }
         },
-        "models.GeneratedCode": {
+        "models.LessonTeacher": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "expires_at": {
-                    "type": "string"
-                },
                 "id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "lesson_id": {
+                    "type": "integer"
                 },
-                "lesson": {
-                    "$ref": "#/definitions/models.Lesson"
+                "role": {
+                    "description": "'lead' or 'assistant'",
+                    "type": "string"
                 },
-                "lesson_id": {
+                "teacher": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "teacher_id": {
                     "type": "integer"
                 }
             }
         },
-        "models.Group": {
+        "models.Role": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
+                "description": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
+                "system": {
+                    "type": "boolean"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.Lesson": {
+        "models.Term": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "day": {
+                "end_date": {
                     "type": "string"
                 },
-                "group": {
-                    "$ref": "#/definitions/models.Group"
-                },
-                "group_id": {
-                    "type": "integer"
+                "holidays": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Holiday"
+                    }
                 },
                 "id": {
                     "type": "integer"
@@ -844,13 +6616,7 @@ const swaggerJSON = `{
                 "name": {
                     "type": "string"
                 },
-                "room": {
-                    "type": "string"
-                },
-                "teacher": {
-                    "type": "string"
-                },
-                "time": {
+                "start_date": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -867,6 +6633,10 @@ const swaggerJSON = `{
                 "email": {
                     "type": "string"
                 },
+                "email_verified_at": {
+                    "description": "Nil until the user opens the link mailed on registration",
+                    "type": "string"
+                },
                 "group": {
                     "$ref": "#/definitions/models.Group"
                 },
@@ -883,12 +6653,54 @@ const swaggerJSON = `{
                     "type": "string"
                 },
                 "role": {
+                    "description": "'student', 'teacher', 'admin' or 'guardian'",
+                    "type": "string"
+                },
+                "roles": {
+                    "description": "Roles grant permissions; Role above is the primary one the frontend\npicks a portal by",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Role"
+                    }
+                },
+                "status": {
+                    "description": "'active', 'pending' or 'rejected'",
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
+        },
+        "rbac.Permission": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "codes.generate"
+                }
+            }
+        },
+        "throttle.Entry": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "identifier:student001"
+                },
+                "last_failed_at": {
+                    "type": "string"
+                },
+                "locked_until": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -906,6 +6718,7 @@ func SetupRouter(r *gin.Engine, db *gorm.DB) {
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:5174", "http://localhost:5175"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"Content-Disposition"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -916,6 +6729,35 @@ func SetupRouter(r *gin.Engine, db *gorm.DB) {
 		panic(err)
 	}
 
+	// Only trust X-Forwarded-For from configured proxies, so that clients
+	// cannot dodge the per-IP login throttling by spoofing it
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic(err)
+	}
+
+	var throttleStore throttle.Store = throttle.NewPostgresStore(db)
+	if cfg.ThrottleStore == throttle.StoreMemory {
+		throttleStore = throttle.NewMemoryStore()
+	}
+	throttler := throttle.New(throttleStore)
+
+	var mail mailer.Mailer = &mailer.LogMailer{From: cfg.MailFrom}
+	switch cfg.Mailer {
+	case mailer.TransportFile:
+		mail = &mailer.LogMailer{From: cfg.MailFrom, Dir: cfg.MailDir}
+	case mailer.TransportSMTP:
+		mail = &mailer.SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}
+	}
+
+	// storage.BackendLocal is the only backend so far
+	var store storage.Storage = &storage.Local{Dir: cfg.UploadDir}
+
 	// Swagger endpoint
 	r.GET("/swagger.json", func(c *gin.Context) {
 		c.String(http.StatusOK, swaggerJSON)
@@ -930,58 +6772,258 @@ func SetupRouter(r *gin.Engine, db *gorm.DB) {
 	authRoutes := r.Group("/auth")
 	{
 		authRoutes.POST("/login", func(c *gin.Context) {
-			handlers.Login(c, db, cfg)
+			handlers.Login(c, db, cfg, throttler)
 		})
 		authRoutes.POST("/register", func(c *gin.Context) {
-			handlers.Register(c, db, cfg)
+			handlers.Register(c, db, cfg, mail)
+		})
+		authRoutes.POST("/refresh", func(c *gin.Context) {
+			handlers.Refresh(c, db, cfg)
+		})
+		authRoutes.POST("/logout", func(c *gin.Context) {
+			handlers.Logout(c, db)
+		})
+		authRoutes.POST("/forgot-password", func(c *gin.Context) {
+			handlers.ForgotPassword(c, db, cfg, mail)
+		})
+		authRoutes.POST("/reset-password", func(c *gin.Context) {
+			handlers.ResetPassword(c, db, throttler)
+		})
+		authRoutes.GET("/registration-policy", func(c *gin.Context) {
+			handlers.GetRegistrationPolicy(c, cfg)
+		})
+		authRoutes.POST("/verify-email", func(c *gin.Context) {
+			handlers.VerifyEmail(c, db)
+		})
+		authRoutes.POST("/resend-verification", func(c *gin.Context) {
+			handlers.ResendVerification(c, db, cfg, mail)
+		})
+		authRoutes.POST("/guardian-invite/accept", func(c *gin.Context) {
+			handlers.AcceptGuardianInvite(c, db)
 		})
 	}
 
 	// Authenticated routes
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware(cfg))
+	api.Use(middleware.AuthMiddleware(cfg, db))
 	{
 		// Lesson routes (accessible to all authenticated users)
 		api.GET("/lessons", func(c *gin.Context) {
 			handlers.GetLessons(c, db)
 		})
+		api.GET("/terms/current", func(c *gin.Context) {
+			handlers.GetCurrentTerm(c, db)
+		})
+
+		// Routes are grouped by portal; access is decided by permissions, so
+		// one user may use several portals
+		submit := middleware.PermissionMiddleware(rbac.AttendanceSubmit)
+		readOwn := middleware.PermissionMiddleware(rbac.AttendanceReadOwn)
+		teach := middleware.PermissionMiddleware(rbac.LessonsTeach, rbac.LessonsManage)
+		generate := middleware.PermissionMiddleware(rbac.CodesGenerate)
+		readLesson := middleware.PermissionMiddleware(rbac.AttendanceReadLesson, rbac.AttendanceReadAll)
+		invite := middleware.PermissionMiddleware(rbac.GroupsInvite)
+		submitExcuses := middleware.PermissionMiddleware(rbac.ExcusesSubmit)
+		reviewExcuses := middleware.PermissionMiddleware(rbac.ExcusesReview)
 
 		// Student routes
 		studentRoutes := api.Group("/student")
-		studentRoutes.Use(middleware.RoleMiddleware("student"))
 		{
-			studentRoutes.POST("/attendance", func(c *gin.Context) {
-				handlers.SubmitAttendance(c, db)
+			studentRoutes.POST("/attendance", submit, func(c *gin.Context) {
+				handlers.SubmitAttendance(c, db, cfg)
 			})
-			studentRoutes.GET("/attendance", func(c *gin.Context) {
+			studentRoutes.POST("/attendance/scan", submit, func(c *gin.Context) {
+				handlers.ScanAttendance(c, db, cfg)
+			})
+			studentRoutes.GET("/attendance", readOwn, func(c *gin.Context) {
 				handlers.GetStudentAttendance(c, db)
 			})
+			studentRoutes.GET("/guardians", readOwn, func(c *gin.Context) {
+				handlers.GetMyGuardians(c, db)
+			})
+			studentRoutes.POST("/guardians/invites", readOwn, func(c *gin.Context) {
+				handlers.InviteMyGuardian(c, db, cfg, mail)
+			})
+			studentRoutes.DELETE("/guardians/:id", readOwn, func(c *gin.Context) {
+				handlers.RemoveMyGuardian(c, db)
+			})
+			studentRoutes.GET("/excuses", readOwn, func(c *gin.Context) {
+				handlers.GetMyExcuses(c, db)
+			})
+			studentRoutes.POST("/excuses", submitExcuses, func(c *gin.Context) {
+				handlers.SubmitExcuse(c, db, cfg, store)
+			})
+			studentRoutes.GET("/excuses/:id/file", readOwn, func(c *gin.Context) {
+				handlers.GetMyExcuseFile(c, db, store)
+			})
+			studentRoutes.DELETE("/excuses/:id", submitExcuses, func(c *gin.Context) {
+				handlers.WithdrawExcuse(c, db, store)
+			})
 		}
 
 		// Teacher routes
 		teacherRoutes := api.Group("/teacher")
-		teacherRoutes.Use(middleware.RoleMiddleware("teacher"))
 		{
-			teacherRoutes.POST("/lessons/:lessonId/code", func(c *gin.Context) {
-				handlers.GenerateCode(c, db)
+			teacherRoutes.GET("/lessons", teach, func(c *gin.Context) {
+				handlers.GetTeacherLessons(c, db)
+			})
+			teacherRoutes.GET("/lessons/:lessonId/code", generate, func(c *gin.Context) {
+				handlers.GetCurrentCode(c, db, cfg)
+			})
+			teacherRoutes.POST("/lessons/:lessonId/code", generate, func(c *gin.Context) {
+				handlers.GenerateCode(c, db, cfg)
 			})
-			teacherRoutes.DELETE("/lessons/:lessonId/code", func(c *gin.Context) {
+			teacherRoutes.GET("/lessons/:lessonId/attempts", readLesson, func(c *gin.Context) {
+				handlers.GetCodeAttempts(c, db)
+			})
+			teacherRoutes.GET("/lessons/:lessonId/code/qr", generate, func(c *gin.Context) {
+				handlers.GetCodeQR(c, db, cfg)
+			})
+			teacherRoutes.DELETE("/lessons/:lessonId/code", generate, func(c *gin.Context) {
 				handlers.DeactivateCode(c, db)
 			})
-			teacherRoutes.GET("/attendance/:lessonId", func(c *gin.Context) {
+			teacherRoutes.GET("/lessons/:lessonId/sessions", readLesson, func(c *gin.Context) {
+				handlers.GetLessonSessions(c, db)
+			})
+			teacherRoutes.GET("/lessons/:lessonId/roster", readLesson, func(c *gin.Context) {
+				handlers.GetLessonRoster(c, db)
+			})
+			teacherRoutes.GET("/lessons/:lessonId/attendance/changes", readLesson, func(c *gin.Context) {
+				handlers.GetAttendanceChanges(c, db)
+			})
+			teacherRoutes.PUT("/lessons/:lessonId/attendance/:studentId", teach, func(c *gin.Context) {
+				handlers.SetStudentAttendance(c, db)
+			})
+			teacherRoutes.DELETE("/lessons/:lessonId/attendance/:studentId", teach, func(c *gin.Context) {
+				handlers.RemoveStudentAttendance(c, db)
+			})
+			teacherRoutes.GET("/lessons/:lessonId/delegations", teach, func(c *gin.Context) {
+				handlers.GetLessonDelegations(c, db)
+			})
+			teacherRoutes.POST("/lessons/:lessonId/delegations", teach, func(c *gin.Context) {
+				handlers.CreateLessonDelegation(c, db)
+			})
+			teacherRoutes.DELETE("/lessons/:lessonId/delegations/:id", teach, func(c *gin.Context) {
+				handlers.DeleteLessonDelegation(c, db)
+			})
+			teacherRoutes.GET("/attendance/:lessonId", readLesson, func(c *gin.Context) {
 				handlers.GetLessonAttendance(c, db)
 			})
 		}
 
+		// Curator routes, scoped to the groups the user curates
+		curatorRoutes := api.Group("/curator")
+		curatorRoutes.Use(middleware.PermissionMiddleware(rbac.AttendanceReadGroup, rbac.AttendanceReadAll))
+		{
+			curatorRoutes.GET("/groups", func(c *gin.Context) {
+				handlers.GetCuratedGroups(c, db)
+			})
+			curatorRoutes.GET("/groups/:id/students", func(c *gin.Context) {
+				handlers.GetCuratedGroupStudents(c, db)
+			})
+			curatorRoutes.GET("/groups/:id/journal", func(c *gin.Context) {
+				handlers.GetCuratedGroupJournal(c, db)
+			})
+			curatorRoutes.GET("/groups/:id/stats", func(c *gin.Context) {
+				handlers.GetCuratedGroupStats(c, db)
+			})
+			curatorRoutes.GET("/groups/:id/invites", invite, func(c *gin.Context) {
+				handlers.GetCuratedInvites(c, db)
+			})
+			curatorRoutes.POST("/groups/:id/invites", invite, func(c *gin.Context) {
+				handlers.CreateCuratedInvite(c, db)
+			})
+			curatorRoutes.DELETE("/invites/:id", invite, func(c *gin.Context) {
+				handlers.RevokeCuratedInvite(c, db)
+			})
+			curatorRoutes.GET("/excuses", func(c *gin.Context) {
+				handlers.GetCuratedExcuses(c, db)
+			})
+			curatorRoutes.GET("/excuses/:id/file", func(c *gin.Context) {
+				handlers.GetCuratedExcuseFile(c, db, store)
+			})
+			curatorRoutes.POST("/excuses/:id/approve", reviewExcuses, func(c *gin.Context) {
+				handlers.ApproveExcuse(c, db, mail)
+			})
+			curatorRoutes.POST("/excuses/:id/reject", reviewExcuses, func(c *gin.Context) {
+				handlers.RejectExcuse(c, db, mail)
+			})
+		}
+
+		// Guardian routes, scoped to the students linked to the user. Any
+		// user may accept an invite, which grants the guardian role.
+		guardianRoutes := api.Group("/guardian")
+		{
+			guardianRoutes.POST("/invites/accept", func(c *gin.Context) {
+				handlers.LinkGuardianInvite(c, db)
+			})
+
+			linked := guardianRoutes.Group("", middleware.PermissionMiddleware(rbac.AttendanceReadLinked))
+			linked.GET("/students", func(c *gin.Context) {
+				handlers.GetGuardianStudents(c, db)
+			})
+			linked.GET("/students/:id/attendance", func(c *gin.Context) {
+				handlers.GetGuardianStudentAttendance(c, db)
+			})
+			linked.GET("/students/:id/stats", func(c *gin.Context) {
+				handlers.GetGuardianStudentStats(c, db)
+			})
+		}
+
 		// Admin routes
 		adminRoutes := api.Group("/admin")
-		adminRoutes.Use(middleware.RoleMiddleware("admin"))
 		{
-			adminRoutes.GET("/users", func(c *gin.Context) { handlers.AdminGetUsers(c, db) })
-			adminRoutes.POST("/users", func(c *gin.Context) { handlers.AdminCreateUser(c, db) })
-			adminRoutes.PUT("/users/:id", func(c *gin.Context) { handlers.AdminUpdateUser(c, db) })
-			adminRoutes.DELETE("/users/:id", func(c *gin.Context) { handlers.AdminDeleteUser(c, db) })
-			adminRoutes.GET("/groups", func(c *gin.Context) { handlers.AdminGetGroups(c, db) })
+			users := adminRoutes.Group("", middleware.PermissionMiddleware(rbac.UsersManage))
+			users.GET("/users", func(c *gin.Context) { handlers.AdminGetUsers(c, db) })
+			users.POST("/users", func(c *gin.Context) { handlers.AdminCreateUser(c, db) })
+			users.PUT("/users/:id", func(c *gin.Context) { handlers.AdminUpdateUser(c, db) })
+			users.DELETE("/users/:id", func(c *gin.Context) { handlers.AdminDeleteUser(c, db, store) })
+			users.DELETE("/users/:id/sessions", func(c *gin.Context) { handlers.AdminRevokeUserSessions(c, db) })
+			users.POST("/users/:id/verify-email", func(c *gin.Context) { handlers.AdminVerifyUserEmail(c, db) })
+			users.GET("/users/:id/guardians", func(c *gin.Context) { handlers.AdminGetStudentGuardians(c, db) })
+			users.POST("/users/:id/guardians", func(c *gin.Context) { handlers.AdminLinkGuardian(c, db) })
+			users.POST("/users/:id/guardians/invites", func(c *gin.Context) { handlers.AdminInviteGuardian(c, db, cfg, mail) })
+			users.DELETE("/guardian-links/:id", func(c *gin.Context) { handlers.AdminUnlinkGuardian(c, db) })
+			users.GET("/registrations", func(c *gin.Context) { handlers.AdminGetRegistrations(c, db) })
+			users.POST("/registrations/:id/approve", func(c *gin.Context) { handlers.AdminApproveRegistration(c, db, mail) })
+			users.POST("/registrations/:id/reject", func(c *gin.Context) { handlers.AdminRejectRegistration(c, db, mail) })
+			users.GET("/lockouts", func(c *gin.Context) { handlers.AdminGetLockouts(c, throttler) })
+			users.DELETE("/lockouts/:key", func(c *gin.Context) { handlers.AdminDeleteLockout(c, throttler) })
+
+			groups := adminRoutes.Group("", middleware.PermissionMiddleware(rbac.GroupsManage))
+			groups.GET("/groups", func(c *gin.Context) { handlers.AdminGetGroups(c, db) })
+			groups.POST("/groups", func(c *gin.Context) { handlers.AdminCreateGroup(c, db) })
+			groups.PUT("/groups/:id", func(c *gin.Context) { handlers.AdminUpdateGroup(c, db) })
+			groups.DELETE("/groups/:id", func(c *gin.Context) { handlers.AdminDeleteGroup(c, db) })
+			groups.GET("/groups/:id/members", func(c *gin.Context) { handlers.AdminGetGroupMembers(c, db) })
+			groups.GET("/groups/:id/lessons", func(c *gin.Context) { handlers.AdminGetGroupLessons(c, db) })
+			groups.PUT("/groups/:id/curator", func(c *gin.Context) { handlers.AdminSetGroupCurator(c, db) })
+			groups.POST("/groups/:id/invites", func(c *gin.Context) { handlers.AdminCreateInvite(c, db) })
+			groups.GET("/invites", func(c *gin.Context) { handlers.AdminGetInvites(c, db) })
+			groups.DELETE("/invites/:id", func(c *gin.Context) { handlers.AdminRevokeInvite(c, db) })
+
+			lessons := adminRoutes.Group("", middleware.PermissionMiddleware(rbac.LessonsManage))
+			lessons.GET("/lessons", func(c *gin.Context) { handlers.AdminGetLessons(c, db) })
+			lessons.POST("/lessons", func(c *gin.Context) { handlers.AdminCreateLesson(c, db) })
+			lessons.PUT("/lessons/:id", func(c *gin.Context) { handlers.AdminUpdateLesson(c, db) })
+			lessons.DELETE("/lessons/:id", func(c *gin.Context) { handlers.AdminDeleteLesson(c, db) })
+
+			terms := adminRoutes.Group("", middleware.PermissionMiddleware(rbac.TermsManage))
+			terms.GET("/terms", func(c *gin.Context) { handlers.AdminGetTerms(c, db) })
+			terms.POST("/terms", func(c *gin.Context) { handlers.AdminCreateTerm(c, db) })
+			terms.PUT("/terms/:id", func(c *gin.Context) { handlers.AdminUpdateTerm(c, db) })
+			terms.DELETE("/terms/:id", func(c *gin.Context) { handlers.AdminDeleteTerm(c, db) })
+			terms.POST("/terms/:id/holidays", func(c *gin.Context) { handlers.AdminCreateHoliday(c, db) })
+			terms.DELETE("/holidays/:id", func(c *gin.Context) { handlers.AdminDeleteHoliday(c, db) })
+
+			roles := adminRoutes.Group("", middleware.PermissionMiddleware(rbac.RolesManage))
+			roles.GET("/permissions", func(c *gin.Context) { handlers.AdminGetPermissions(c) })
+			roles.GET("/roles", func(c *gin.Context) { handlers.AdminGetRoles(c, db) })
+			roles.POST("/roles", func(c *gin.Context) { handlers.AdminCreateRole(c, db) })
+			roles.PUT("/roles/:id", func(c *gin.Context) { handlers.AdminUpdateRole(c, db) })
+			roles.DELETE("/roles/:id", func(c *gin.Context) { handlers.AdminDeleteRole(c, db) })
+			roles.GET("/users/:id/roles", func(c *gin.Context) { handlers.AdminGetUserRoles(c, db) })
+			roles.PUT("/users/:id/roles", func(c *gin.Context) { handlers.AdminSetUserRoles(c, db) })
 		}
 	}
 }
@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"student-attendance-app/pkg/models"
+	"time"
+)
+
+// Weekdays lists the day names stored in models.Lesson.Day, starting from Monday.
+var Weekdays = []string{"Понедельник", "Вторник", "Среда", "Четверг", "Пятница", "Суббота", "Воскресенье"}
+
+// IsValidDay reports whether day is one of Weekdays.
+func IsValidDay(day string) bool {
+	for _, d := range Weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// WeekdayName returns the Weekdays entry for the day of t.
+func WeekdayName(t time.Time) string {
+	// time.Weekday starts from Sunday, Weekdays starts from Monday.
+	return Weekdays[(int(t.Weekday())+6)%7]
+}
+
+// DateOf returns the calendar date of t as midnight UTC, which is how dates
+// are stored in date columns.
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// WeekNumber returns the teaching week of day. Weeks are counted from 1
+// starting with the week the term begins in.
+func WeekNumber(term models.Term, day time.Time) int {
+	start := DateOf(term.StartDate)
+	start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7)) // Monday of the first week
+	return int(DateOf(day).Sub(start).Hours()/24)/7 + 1
+}
+
+// MatchesWeek reports whether the lesson's week parity allows it to take
+// place in the week of day. Weeks are only counted within a term, so parity
+// is ignored when term is nil; calendar week numbers would repeat their
+// parity across a New Year with 53 ISO weeks.
+func MatchesWeek(lesson models.Lesson, term *models.Term, day time.Time) bool {
+	if term == nil {
+		return true
+	}
+	switch lesson.WeekParity {
+	case models.WeekOdd:
+		return WeekNumber(*term, day)%2 == 1
+	case models.WeekEven:
+		return WeekNumber(*term, day)%2 == 0
+	}
+	return true
+}
+
+// IsValidWeekParity reports whether parity is one of the models.Week* values.
+func IsValidWeekParity(parity string) bool {
+	return parity == models.WeekEvery || parity == models.WeekOdd || parity == models.WeekEven
+}
+
+// ParseTimeRange parses a lesson time such as "09:00-10:30" and returns the
+// start and end as offsets from midnight.
+func ParseTimeRange(s string) (start, end time.Duration, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("time must be in HH:MM-HH:MM format")
+	}
+
+	if start, err = parseClock(parts[0]); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(parts[1]); err != nil {
+		return 0, 0, err
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("lesson must end after it starts")
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
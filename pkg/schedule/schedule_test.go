@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"student-attendance-app/pkg/models"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWeekdayName(t *testing.T) {
+	tests := []struct {
+		day  time.Time
+		want string
+	}{
+		{date(2025, 9, 1), "Понедельник"},
+		{date(2025, 9, 3), "Среда"},
+		{date(2025, 9, 6), "Суббота"},
+		{date(2025, 9, 7), "Воскресенье"},
+	}
+	for _, tt := range tests {
+		if got := WeekdayName(tt.day); got != tt.want {
+			t.Errorf("WeekdayName(%s) = %q, want %q", tt.day.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
+func TestDateOf(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	tests := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"midnight UTC", date(2025, 9, 1), date(2025, 9, 1)},
+		{"end of day", time.Date(2025, 9, 1, 23, 59, 59, 0, time.UTC), date(2025, 9, 1)},
+		// The calendar date is the one of t's own location
+		{"early morning in another zone", time.Date(2025, 9, 2, 1, 0, 0, 0, moscow), date(2025, 9, 2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DateOf(tt.in); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("DateOf(%v) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	tests := []struct {
+		in         string
+		start, end time.Duration
+		wantErr    bool
+	}{
+		{"09:00-10:30", 9 * time.Hour, 10*time.Hour + 30*time.Minute, false},
+		{" 08:15 - 09:00 ", 8*time.Hour + 15*time.Minute, 9 * time.Hour, false},
+		{"10:30-09:00", 0, 0, true},
+		{"09:00-09:00", 0, 0, true},
+		{"09:00", 0, 0, true},
+		{"9am-10am", 0, 0, true},
+	}
+	for _, tt := range tests {
+		start, end, err := ParseTimeRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimeRange(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if start != tt.start || end != tt.end {
+			t.Errorf("ParseTimeRange(%q) = %v, %v, want %v, %v", tt.in, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestWeekNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		term models.Term
+		day  time.Time
+		want int
+	}{
+		{"Monday before the first day of term", autumnTerm, date(2025, 9, 1), 1},
+		{"first day of term", autumnTerm, date(2025, 9, 3), 1},
+		{"Sunday of the first week", autumnTerm, date(2025, 9, 7), 1},
+		{"Monday of the second week", autumnTerm, date(2025, 9, 8), 2},
+		{"last week of autumn", autumnTerm, date(2025, 12, 22), 17},
+		{"first week of spring starts over", springTerm, date(2026, 2, 2), 1},
+		{"second week of spring", springTerm, date(2026, 2, 13), 2},
+		{"time of day is ignored", springTerm, time.Date(2026, 2, 8, 23, 59, 0, 0, time.UTC), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekNumber(tt.term, tt.day); got != tt.want {
+				t.Errorf("WeekNumber(%s) = %d, want %d", tt.day.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesWeek(t *testing.T) {
+	odd := models.Lesson{WeekParity: models.WeekOdd}
+	even := models.Lesson{WeekParity: models.WeekEven}
+	every := models.Lesson{WeekParity: models.WeekEvery}
+
+	tests := []struct {
+		name   string
+		lesson models.Lesson
+		term   *models.Term
+		day    time.Time
+		want   bool
+	}{
+		{"odd lesson in the last, odd, autumn week", odd, &autumnTerm, date(2025, 12, 22), true},
+		{"even lesson in the last, odd, autumn week", even, &autumnTerm, date(2025, 12, 22), false},
+		// Week 1 of spring is odd although ISO week 6 is even
+		{"odd lesson in the first spring week", odd, &springTerm, date(2026, 2, 2), true},
+		{"even lesson in the first spring week", even, &springTerm, date(2026, 2, 2), false},
+		{"even lesson in the second spring week", even, &springTerm, date(2026, 2, 9), true},
+		{"every week lesson", every, &springTerm, date(2026, 2, 9), true},
+		// Without a term parity is ignored: 2026 has 53 ISO weeks, so ISO
+		// week 53 and the following week 1 would both be odd
+		{"odd lesson without a term in ISO week 53", odd, nil, date(2026, 12, 28), true},
+		{"odd lesson without a term in the next ISO week 1", odd, nil, date(2027, 1, 4), true},
+		{"even lesson without a term", even, nil, date(2027, 1, 4), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesWeek(tt.lesson, tt.term, tt.day); got != tt.want {
+				t.Errorf("MatchesWeek = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"fmt"
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Occurs reports whether the weekly lesson takes place on the given day,
+// taking the lesson's term, its holidays and the lesson's week parity into
+// account.
+func Occurs(db *gorm.DB, lesson models.Lesson, day time.Time) (bool, error) {
+	if lesson.Day != WeekdayName(day) {
+		return false, nil
+	}
+	if lesson.TermID == nil {
+		return MatchesWeek(lesson, nil, day), nil
+	}
+
+	var term models.Term
+	if err := db.First(&term, *lesson.TermID).Error; err != nil {
+		return false, err
+	}
+	teaching, err := IsTeachingDay(db, term, day)
+	if err != nil || !teaching {
+		return false, err
+	}
+	return MatchesWeek(lesson, &term, day), nil
+}
+
+// Lateness classifies a mark submitted at the given time for the session. It
+// is late when submitted more than grace after the scheduled start, in which
+// case minutesLate counts whole minutes since the start.
+func Lateness(session models.LessonSession, submittedAt time.Time, grace time.Duration) (status string, minutesLate int) {
+	late := submittedAt.Sub(session.StartsAt)
+	if late <= grace {
+		return models.AttendancePresent, 0
+	}
+	return models.AttendanceLate, int(late / time.Minute)
+}
+
+// EnsureSession returns the session of lesson on the calendar date of day,
+// creating it from the weekly template when it does not exist yet.
+func EnsureSession(db *gorm.DB, lesson models.Lesson, day time.Time) (models.LessonSession, error) {
+	date := DateOf(day)
+	session := models.LessonSession{LessonID: lesson.ID, Date: date}
+
+	start, end, err := ParseTimeRange(lesson.Time)
+	if err != nil {
+		return session, fmt.Errorf("lesson %d: %w", lesson.ID, err)
+	}
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	session.StartsAt = midnight.Add(start)
+	session.EndsAt = midnight.Add(end)
+
+	// Concurrent callers may race to create the same session, so let the
+	// unique index decide and read back whichever row won.
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&session).Error; err != nil {
+		return session, err
+	}
+	err = db.Where("lesson_id = ? AND date = ?", lesson.ID, date).First(&session).Error
+	return session, err
+}
@@ -0,0 +1,190 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"student-attendance-app/pkg/models"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// An autumn term starting on a Wednesday and a spring term after the winter
+// break.
+var (
+	autumnTerm = models.Term{ID: 1, Name: "Осень 2025", StartDate: date(2025, 9, 3), EndDate: date(2025, 12, 28)}
+	springTerm = models.Term{ID: 2, Name: "Весна 2026", StartDate: date(2026, 2, 2), EndDate: date(2026, 6, 30)}
+)
+
+// stubDriver answers the two queries Occurs makes, loading a term by ID and
+// counting its holidays on a date, from the terms and holidays below.
+type stubDriver struct{}
+
+var (
+	stubTerms    = []models.Term{autumnTerm, springTerm}
+	stubHolidays = []models.Holiday{
+		{TermID: autumnTerm.ID, Date: date(2025, 11, 4)},
+		{TermID: springTerm.ID, Date: date(2026, 2, 23)},
+	}
+)
+
+func init() {
+	sql.Register("schedule-stub", stubDriver{})
+}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("stub: prepare") }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, errors.New("stub: begin") }
+
+func (stubConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, `FROM "terms"`):
+		rows := &stubRows{columns: []string{"id", "name", "start_date", "end_date"}}
+		for _, term := range stubTerms {
+			if int64(term.ID) == args[0].Value.(int64) {
+				rows.values = append(rows.values, []driver.Value{int64(term.ID), term.Name, term.StartDate, term.EndDate})
+			}
+		}
+		return rows, nil
+	case strings.Contains(query, `FROM "holidays"`):
+		var count int64
+		for _, holiday := range stubHolidays {
+			if int64(holiday.TermID) == args[0].Value.(int64) && holiday.Date.Equal(args[1].Value.(time.Time)) {
+				count++
+			}
+		}
+		return &stubRows{columns: []string{"count"}, values: [][]driver.Value{{count}}}, nil
+	}
+	return nil, errors.New("stub: unexpected query " + query)
+}
+
+type stubRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func openStub(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DriverName: "schedule-stub"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open stub database: %v", err)
+	}
+	return db
+}
+
+func termID(term models.Term) *uint { return &term.ID }
+
+func TestIsTeachingDay(t *testing.T) {
+	db := openStub(t)
+	tests := []struct {
+		name string
+		term models.Term
+		day  time.Time
+		want bool
+	}{
+		{"first day of term", autumnTerm, date(2025, 9, 3), true},
+		{"last day of term", autumnTerm, date(2025, 12, 28), true},
+		{"day before the term", autumnTerm, date(2025, 9, 2), false},
+		{"day after the term", autumnTerm, date(2025, 12, 29), false},
+		{"holiday", autumnTerm, date(2025, 11, 4), false},
+		{"time of day is ignored", autumnTerm, time.Date(2025, 12, 28, 23, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsTeachingDay(db, tt.term, tt.day)
+			if err != nil {
+				t.Fatalf("IsTeachingDay failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsTeachingDay(%s) = %v, want %v", tt.day.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurs(t *testing.T) {
+	db := openStub(t)
+	tuesday := models.Lesson{Day: "Вторник", TermID: termID(autumnTerm)}
+	tuesdayOdd := models.Lesson{Day: "Вторник", WeekParity: models.WeekOdd, TermID: termID(autumnTerm)}
+	mondaySpring := models.Lesson{Day: "Понедельник", TermID: termID(springTerm)}
+	mondayNoTerm := models.Lesson{Day: "Понедельник"}
+
+	tests := []struct {
+		name   string
+		lesson models.Lesson
+		day    time.Time
+		want   bool
+	}{
+		{"teaching day", tuesday, date(2025, 10, 28), true},
+		{"holiday is skipped", tuesday, date(2025, 11, 4), false},
+		{"week after the holiday", tuesday, date(2025, 11, 11), true},
+		{"other weekday", tuesday, date(2025, 10, 29), false},
+		{"before the term", tuesday, date(2025, 9, 2), false},
+		{"after the term", tuesday, date(2025, 12, 30), false},
+		{"odd week", tuesdayOdd, date(2025, 11, 11), true},
+		{"even week", tuesdayOdd, date(2025, 11, 18), false},
+		{"odd week on a holiday", tuesdayOdd, date(2025, 11, 4), false},
+		{"spring teaching day", mondaySpring, date(2026, 2, 16), true},
+		{"spring holiday is skipped", mondaySpring, date(2026, 2, 23), false},
+		{"lesson without a term", mondayNoTerm, date(2025, 11, 3), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Occurs(db, tt.lesson, tt.day)
+			if err != nil {
+				t.Fatalf("Occurs failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Occurs(%s, %s) = %v, want %v", tt.lesson.Day, tt.day.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLateness(t *testing.T) {
+	start := time.Date(2025, 10, 28, 9, 0, 0, 0, time.UTC)
+	session := models.LessonSession{StartsAt: start, EndsAt: start.Add(90 * time.Minute)}
+	grace := 10 * time.Minute
+	tests := []struct {
+		name        string
+		submittedAt time.Time
+		status      string
+		minutesLate int
+	}{
+		{"before the start", start.Add(-5 * time.Minute), models.AttendancePresent, 0},
+		{"at the start", start, models.AttendancePresent, 0},
+		{"at the end of the grace period", start.Add(grace), models.AttendancePresent, 0},
+		{"just after the grace period", start.Add(grace + time.Second), models.AttendanceLate, 10},
+		{"half an hour late", start.Add(30*time.Minute + 59*time.Second), models.AttendanceLate, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, minutesLate := Lateness(session, tt.submittedAt, grace)
+			if status != tt.status || minutesLate != tt.minutesLate {
+				t.Errorf("Lateness = %s, %d, want %s, %d", status, minutesLate, tt.status, tt.minutesLate)
+			}
+		})
+	}
+}
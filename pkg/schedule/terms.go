@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CurrentTerm returns the term containing the calendar date of day, or nil
+// when no term is in progress.
+func CurrentTerm(db *gorm.DB, day time.Time) (*models.Term, error) {
+	var term models.Term
+	date := DateOf(day)
+	err := db.Where("start_date <= ? AND end_date >= ?", date, date).Order("start_date desc").First(&term).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+// IsTeachingDay reports whether the calendar date of day falls within the
+// term and is not one of its holidays.
+func IsTeachingDay(db *gorm.DB, term models.Term, day time.Time) (bool, error) {
+	date := DateOf(day)
+	if date.Before(DateOf(term.StartDate)) || date.After(DateOf(term.EndDate)) {
+		return false, nil
+	}
+	var holidays int64
+	if err := db.Model(&models.Holiday{}).Where("term_id = ? AND date = ?", term.ID, date).Count(&holidays).Error; err != nil {
+		return false, err
+	}
+	return holidays == 0, nil
+}
@@ -0,0 +1,25 @@
+// Package storage keeps uploaded files, such as the documents attached to
+// absence excuses.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// Backends a Storage can use.
+const (
+	// BackendLocal keeps files in a directory on the server's disk.
+	BackendLocal = "local"
+)
+
+// ErrNotFound is returned when no file is stored under a key.
+var ErrNotFound = errors.New("storage: file not found")
+
+// Storage saves files under keys chosen by the caller. Keys are
+// slash-separated relative paths such as "excuses/12/abc.pdf".
+type Storage interface {
+	Put(key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
@@ -0,0 +1,81 @@
+package throttle
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps counters in a map. It suits a single server instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry)}
+}
+
+func (s *MemoryStore) Get(key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries[key], nil
+}
+
+func (s *MemoryStore) Fail(key string, now time.Time, window time.Duration) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || entry.LastFailedAt.Before(now.Add(-window)) {
+		entry = Entry{Key: key}
+	}
+	entry.Failures++
+	entry.LastFailedAt = now
+	s.entries[key] = entry
+	s.prune(now, window)
+	return entry, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = Entry{Key: key}
+	}
+	entry.LockedUntil = &until
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) List(since time.Time) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := []Entry{}
+	for _, entry := range s.entries {
+		if entry.LastFailedAt.After(since) || (entry.LockedUntil != nil && entry.LockedUntil.After(since)) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastFailedAt.After(entries[j].LastFailedAt) })
+	return entries, nil
+}
+
+// prune drops entries that are neither locked nor inside window, so that
+// failures from many addresses do not grow the map forever. The caller holds
+// s.mu.
+func (s *MemoryStore) prune(now time.Time, window time.Duration) {
+	for key, entry := range s.entries {
+		if entry.LastFailedAt.Before(now.Add(-window)) && !entry.Locked(now) {
+			delete(s.entries, key)
+		}
+	}
+}
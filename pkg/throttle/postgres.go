@@ -0,0 +1,77 @@
+package throttle
+
+import (
+	"student-attendance-app/pkg/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresStore keeps counters in the login_throttles table, so lockouts are
+// shared by every server instance using the database.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore returns a store backed by db.
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func toEntry(row models.LoginThrottle) Entry {
+	return Entry{Key: row.Key, Failures: row.Failures, LastFailedAt: row.LastFailedAt, LockedUntil: row.LockedUntil}
+}
+
+func (s *PostgresStore) Get(key string) (Entry, error) {
+	var row models.LoginThrottle
+	err := s.db.Where("key = ?", key).First(&row).Error
+	if err == gorm.ErrRecordNotFound {
+		return Entry{}, nil
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	return toEntry(row), nil
+}
+
+func (s *PostgresStore) Fail(key string, now time.Time, window time.Duration) (Entry, error) {
+	row := models.LoginThrottle{Key: key, Failures: 1, LastFailedAt: now}
+	// Increment in a single statement so concurrent failures are all counted
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":       gorm.Expr("CASE WHEN login_throttles.last_failed_at < ? THEN 1 ELSE login_throttles.failures + 1 END", now.Add(-window)),
+			"last_failed_at": now,
+		}),
+	}).Create(&row).Error
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := s.db.Where("key = ?", key).First(&row).Error; err != nil {
+		return Entry{}, err
+	}
+	return toEntry(row), nil
+}
+
+func (s *PostgresStore) Lock(key string, until time.Time) error {
+	return s.db.Model(&models.LoginThrottle{}).Where("key = ?", key).Update("locked_until", until).Error
+}
+
+func (s *PostgresStore) Delete(key string) error {
+	return s.db.Where("key = ?", key).Delete(&models.LoginThrottle{}).Error
+}
+
+func (s *PostgresStore) List(since time.Time) ([]Entry, error) {
+	var rows []models.LoginThrottle
+	if err := s.db.Where("last_failed_at > ? OR locked_until > ?", since, since).
+		Order("last_failed_at desc").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, toEntry(row))
+	}
+	return entries, nil
+}
@@ -0,0 +1,184 @@
+// Package throttle slows down repeated failed logins. Failures are counted
+// per key, one for the identifier and one for the client IP, and once a key
+// has used up its free failures it is locked out for an exponentially growing
+// period.
+package throttle
+
+import (
+	"strings"
+	"time"
+)
+
+// Stores a Throttler can keep its counters in.
+const (
+	// StoreMemory keeps counters in the server process; they are lost on
+	// restart and not shared between instances.
+	StoreMemory = "memory"
+	// StorePostgres keeps counters in the login_throttles table so all
+	// instances see the same lockouts.
+	StorePostgres = "postgres"
+)
+
+// Entry is the failure counter of one key.
+type Entry struct {
+	Key          string     `json:"key" example:"identifier:student001"`
+	Failures     int        `json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+// Locked reports whether the entry is locked out at now.
+func (e Entry) Locked(now time.Time) bool {
+	return e.LockedUntil != nil && e.LockedUntil.After(now)
+}
+
+// Store keeps failure counters. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// Get returns the entry for key, or a zero Entry if there is none.
+	Get(key string) (Entry, error)
+	// Fail counts a failure for key and returns the updated entry. Counting
+	// starts over when the previous failure is older than window.
+	Fail(key string, now time.Time, window time.Duration) (Entry, error)
+	// Lock locks key out until the given time.
+	Lock(key string, until time.Time) error
+	// Delete removes the entry for key.
+	Delete(key string) error
+	// List returns the entries with a failure after since.
+	List(since time.Time) ([]Entry, error)
+}
+
+// Backoff decides when repeated failures lead to a lockout and for how long.
+type Backoff struct {
+	FreeFailures int           // Failures allowed before the first lockout
+	BaseLockout  time.Duration // First lockout, doubled for every further failure
+	MaxLockout   time.Duration
+}
+
+// Lockout returns how long a key is locked out after failures failures.
+func (b Backoff) Lockout(failures int) time.Duration {
+	if failures <= b.FreeFailures {
+		return 0
+	}
+	lockout := b.BaseLockout
+	for i := b.FreeFailures + 1; i < failures; i++ {
+		lockout *= 2
+		if lockout >= b.MaxLockout {
+			return b.MaxLockout
+		}
+	}
+	return lockout
+}
+
+// Policy is the Backoff of a kind of key together with how long its failures
+// are remembered.
+type Policy struct {
+	Backoff
+	Window time.Duration // Failures are forgotten after this long without one
+}
+
+// Default policies. A single account is protected more strictly than an IP
+// address, which may be shared by a whole classroom behind NAT.
+var (
+	IdentifierPolicy = Policy{Backoff{FreeFailures: 5, BaseLockout: 30 * time.Second, MaxLockout: 15 * time.Minute}, time.Hour}
+	IPPolicy         = Policy{Backoff{FreeFailures: 20, BaseLockout: 30 * time.Second, MaxLockout: 15 * time.Minute}, time.Hour}
+)
+
+// Key prefixes telling identifier and IP counters apart.
+const (
+	identifierPrefix = "identifier:"
+	ipPrefix         = "ip:"
+)
+
+// IdentifierKey returns the key counting failures for a login identifier.
+func IdentifierKey(identifier string) string {
+	return identifierPrefix + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// IPKey returns the key counting failures from a client IP.
+func IPKey(ip string) string {
+	return ipPrefix + ip
+}
+
+// Throttler applies IdentifierPolicy and IPPolicy on top of a Store.
+type Throttler struct {
+	store Store
+}
+
+// New returns a Throttler keeping its counters in store.
+func New(store Store) *Throttler {
+	return &Throttler{store: store}
+}
+
+func policyFor(key string) Policy {
+	if strings.HasPrefix(key, ipPrefix) {
+		return IPPolicy
+	}
+	return IdentifierPolicy
+}
+
+// LockedUntil returns the latest lockout among keys, or nil if none of them
+// is locked out at now.
+func (t *Throttler) LockedUntil(now time.Time, keys ...string) (*time.Time, error) {
+	var until *time.Time
+	for _, key := range keys {
+		entry, err := t.store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Locked(now) && (until == nil || entry.LockedUntil.After(*until)) {
+			until = entry.LockedUntil
+		}
+	}
+	return until, nil
+}
+
+// Fail counts a failure for every key and returns the latest resulting
+// lockout, or nil if none of them is locked out yet.
+func (t *Throttler) Fail(now time.Time, keys ...string) (*time.Time, error) {
+	var until *time.Time
+	for _, key := range keys {
+		policy := policyFor(key)
+		entry, err := t.store.Fail(key, now, policy.Window)
+		if err != nil {
+			return nil, err
+		}
+		lockout := policy.Lockout(entry.Failures)
+		if lockout == 0 {
+			continue
+		}
+		lockedUntil := now.Add(lockout)
+		if err := t.store.Lock(key, lockedUntil); err != nil {
+			return nil, err
+		}
+		if until == nil || lockedUntil.After(*until) {
+			until = &lockedUntil
+		}
+	}
+	return until, nil
+}
+
+// Reset forgets the failures of key, e.g. after a successful login.
+func (t *Throttler) Reset(key string) error {
+	return t.store.Delete(key)
+}
+
+// Entries returns the counters that are still relevant at now: locked out
+// keys and keys with failures inside their window.
+func (t *Throttler) Entries(now time.Time) ([]Entry, error) {
+	window := IdentifierPolicy.Window
+	if IPPolicy.Window > window {
+		window = IPPolicy.Window
+	}
+	entries, err := t.store.List(now.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	relevant := entries[:0]
+	for _, entry := range entries {
+		if entry.Locked(now) || entry.LastFailedAt.After(now.Add(-policyFor(entry.Key).Window)) {
+			relevant = append(relevant, entry)
+		}
+	}
+	return relevant, nil
+}
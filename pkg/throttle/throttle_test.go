@@ -0,0 +1,125 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffLockout(t *testing.T) {
+	backoff := Backoff{FreeFailures: 3, BaseLockout: 5 * time.Second, MaxLockout: time.Minute}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, 5 * time.Second},
+		{5, 10 * time.Second},
+		{6, 20 * time.Second},
+		{7, 40 * time.Second},
+		{8, time.Minute},
+		{9, time.Minute},
+		// Large counts must not overflow into short or negative lockouts
+		{100, time.Minute},
+		{1 << 20, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := backoff.Lockout(tt.failures); got != tt.want {
+			t.Errorf("Lockout(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStoreFail(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	window := time.Hour
+	tests := []struct {
+		name  string
+		fails []time.Duration // Offsets from now of the failures
+		want  int
+	}{
+		{"first failure", []time.Duration{0}, 1},
+		{"failures inside the window add up", []time.Duration{0, time.Minute, 59 * time.Minute}, 3},
+		{"failure after the window starts over", []time.Duration{0, time.Minute, time.Minute + window + time.Second}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			var entry Entry
+			for _, offset := range tt.fails {
+				var err error
+				if entry, err = store.Fail("ip:10.0.0.1", now.Add(offset), window); err != nil {
+					t.Fatalf("Fail failed: %v", err)
+				}
+			}
+			if entry.Failures != tt.want {
+				t.Errorf("Failures = %d, want %d", entry.Failures, tt.want)
+			}
+		})
+	}
+}
+
+func TestThrottlerBackoff(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	throttler := New(NewMemoryStore())
+	key := IdentifierKey(" Student001 ")
+
+	// Each failure comes right after the previous lockout ended
+	at := now
+	for failures := 1; failures <= IdentifierPolicy.FreeFailures+6; failures++ {
+		until, err := throttler.Fail(at, key)
+		if err != nil {
+			t.Fatalf("Fail failed: %v", err)
+		}
+		want := IdentifierPolicy.Lockout(failures)
+		if want == 0 {
+			if until != nil {
+				t.Fatalf("failure %d locked out until %v, want no lockout", failures, until)
+			}
+			continue
+		}
+		if until == nil || until.Sub(at) != want {
+			t.Fatalf("failure %d locked out until %v, want %v after %v", failures, until, want, at)
+		}
+
+		locked, err := throttler.LockedUntil(until.Add(-time.Second), IdentifierKey("student001"))
+		if err != nil {
+			t.Fatalf("LockedUntil failed: %v", err)
+		}
+		if locked == nil || !locked.Equal(*until) {
+			t.Fatalf("LockedUntil before the lockout ends = %v, want %v", locked, until)
+		}
+		if locked, _ := throttler.LockedUntil(*until, key); locked != nil {
+			t.Fatalf("LockedUntil when the lockout ends = %v, want nil", locked)
+		}
+		at = *until
+	}
+
+	if err := throttler.Reset(key); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if until, _ := throttler.Fail(at, key); until != nil {
+		t.Errorf("first failure after Reset locked out until %v", until)
+	}
+}
+
+func TestThrottlerFailReturnsLatestLockout(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	throttler := New(NewMemoryStore())
+	identifier, ip := IdentifierKey("student001"), IPKey("10.0.0.1")
+
+	// The identifier runs out of free failures long before the IP does
+	var until *time.Time
+	for i := 0; i <= IdentifierPolicy.FreeFailures; i++ {
+		var err error
+		if until, err = throttler.Fail(now, identifier, ip); err != nil {
+			t.Fatalf("Fail failed: %v", err)
+		}
+	}
+	if until == nil || !until.Equal(now.Add(IdentifierPolicy.BaseLockout)) {
+		t.Fatalf("Fail = %v, want the identifier lockout", until)
+	}
+	if locked, _ := throttler.LockedUntil(now, ip); locked != nil {
+		t.Errorf("IP locked out until %v after %d failures", locked, IdentifierPolicy.FreeFailures+1)
+	}
+}
@@ -5,6 +5,12 @@ import RegisterPage from './pages/RegisterPage';
 import StudentPage from './pages/StudentPage';
 import TeacherPage from './pages/TeacherPage';
 import AdminPage from './pages/AdminPage';
+import CheckinPage from './pages/CheckinPage';
+import ForgotPasswordPage from './pages/ForgotPasswordPage';
+import ResetPasswordPage from './pages/ResetPasswordPage';
+import VerifyEmailPage from './pages/VerifyEmailPage';
+import GuardianPage from './pages/GuardianPage';
+import GuardianInvitePage from './pages/GuardianInvitePage';
 import './App.css';
 
 const App = () => {
@@ -16,6 +22,12 @@ const App = () => {
         <Route path="/student" element={<StudentPage />} />
         <Route path="/teacher" element={<TeacherPage />} />
         <Route path="/admin" element={<AdminPage />} />
+        <Route path="/checkin" element={<CheckinPage />} />
+        <Route path="/forgot-password" element={<ForgotPasswordPage />} />
+        <Route path="/reset-password" element={<ResetPasswordPage />} />
+        <Route path="/verify-email" element={<VerifyEmailPage />} />
+        <Route path="/guardian" element={<GuardianPage />} />
+        <Route path="/guardian-invite" element={<GuardianInvitePage />} />
         <Route path="*" element={<Navigate to="/" />} />
       </Routes>
     </Router>
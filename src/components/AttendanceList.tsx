@@ -1,26 +1,73 @@
-import type { AttendanceRecord } from '../types';
+import type { CodeAttempt, Roster, RosterEntry } from '../types';
+import { statusLabel } from '../utils/status';
 
 interface AttendanceListProps {
-  attendanceRecords: AttendanceRecord[];
+  roster: Roster | null;
+  codeAttempts?: CodeAttempt[];
+  // Called when the teacher picks another status; 'absent' removes the mark
+  onStatusChange?: (entry: RosterEntry, status: RosterEntry['status']) => void;
 }
 
-const AttendanceList = ({ attendanceRecords }: AttendanceListProps) => {
+const AttendanceList = ({ roster, codeAttempts = [], onStatusChange }: AttendanceListProps) => {
   return (
     <div className="attendance-list">
-      {attendanceRecords.length === 0 ? (
-        <p>На данный момент отметившихся нет.</p>
+      {!roster || roster.students.length === 0 ? (
+        <p>В группе занятия нет студентов.</p>
       ) : (
-        <ul>
-          {attendanceRecords.map(record => (
-            <li key={record.id}>
-              <span>{record.student.name}</span>
-              <span className="timestamp">{new Date(record.submitted_at).toLocaleTimeString('ru-RU')}</span>
-            </li>
-          ))}
-        </ul>
+        <>
+          <p className="roster-totals">
+            Всего: {roster.totals.expected} · присутствуют: {roster.totals.present.count} ({roster.totals.present.percent}%)
+            · опоздали: {roster.totals.late.count} ({roster.totals.late.percent}%)
+            · отсутствуют: {roster.totals.absent.count} ({roster.totals.absent.percent}%)
+            {roster.totals.excused.count > 0 && ` · по уважительной причине: ${roster.totals.excused.count} (${roster.totals.excused.percent}%)`}
+          </p>
+          <ul>
+            {roster.students.map(entry => (
+              <li key={entry.student.id} className={`status-${entry.status}`}>
+                <span>{entry.student.name}</span>
+                <span
+                  className="status"
+                  title={entry.marked_by ? `Отметил(а): ${entry.marked_by.name}` : undefined}
+                >
+                  {statusLabel(entry)}
+                </span>
+                <span className="timestamp">
+                  {entry.submitted_at ? new Date(entry.submitted_at).toLocaleTimeString('ru-RU') : '—'}
+                </span>
+                {onStatusChange && (
+                  <select
+                    value={entry.status}
+                    onChange={e => onStatusChange(entry, e.target.value as RosterEntry['status'])}
+                  >
+                    <option value="present">присутствовал</option>
+                    <option value="late">опоздал</option>
+                    <option value="excused">уважительная причина</option>
+                    <option value="absent">отсутствовал</option>
+                  </select>
+                )}
+              </li>
+            ))}
+          </ul>
+        </>
+      )}
+      {codeAttempts.length > 0 && (
+        <>
+          <h4>Неверные коды</h4>
+          <ul className="code-attempts">
+            {codeAttempts.map(attempt => (
+              <li key={attempt.id}>
+                <span>{attempt.student.name}</span>
+                <span>
+                  ошибок: {attempt.failures}
+                  {attempt.locked_until && new Date(attempt.locked_until) > new Date() && ' (заблокирован)'}
+                </span>
+              </li>
+            ))}
+          </ul>
+        </>
       )}
     </div>
   );
 };
 
-export default AttendanceList; 
\ No newline at end of file
+export default AttendanceList;
@@ -4,12 +4,13 @@ import './CodeDisplayModal.css';
 interface CodeDisplayModalProps {
   lesson: Lesson;
   code: string;
+  qrSrc?: string | null;
   isOpen: boolean;
   onClose: () => void;
   onStop: () => void;
 }
 
-const CodeDisplayModal = ({ lesson, code, isOpen, onClose, onStop }: CodeDisplayModalProps) => {
+const CodeDisplayModal = ({ lesson, code, qrSrc, isOpen, onClose, onStop }: CodeDisplayModalProps) => {
   if (!isOpen) return null;
 
   return (
@@ -21,6 +22,7 @@ const CodeDisplayModal = ({ lesson, code, isOpen, onClose, onStop }: CodeDisplay
           <p>{lesson.name} ({lesson.day}, {lesson.time})</p>
         </div>
         <div className="code-display-box">
+          {qrSrc && <img src={qrSrc} alt="QR-код для отметки" className="code-qr" />}
           <div className="code">{code}</div>
           <p className="expiry-info">Этот код действителен в течение 5 минут.</p>
         </div>
@@ -14,16 +14,17 @@ const CodeEntryModal = ({ lesson, isOpen, onClose, onSubmit }: CodeEntryModalPro
 
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault();
-    if (code.length !== 5) {
-      setError('Код должен содержать 5 цифр');
+    const normalized = code.trim().toUpperCase();
+    if (normalized.length < 4 || normalized.length > 12) {
+      setError('Код должен содержать от 4 до 12 символов');
       return;
     }
-    if (!/^\d{5}$/.test(code)) {
-      setError('Код должен содержать только цифры');
+    if (!/^[0-9A-Z]+$/.test(normalized)) {
+      setError('Код должен содержать только цифры и латинские буквы');
       return;
     }
     setError('');
-    onSubmit(code);
+    onSubmit(normalized);
     setCode('');
   };
 
@@ -43,14 +44,14 @@ const CodeEntryModal = ({ lesson, isOpen, onClose, onSubmit }: CodeEntryModalPro
           
           <form onSubmit={handleSubmit}>
             <div className="code-input-group">
-              <label htmlFor="code">Введите 5-значный код:</label>
+              <label htmlFor="code">Введите код:</label>
               <input
                 id="code"
                 type="text"
                 value={code}
                 onChange={(e) => setCode(e.target.value)}
                 placeholder="Введите код"
-                maxLength={5}
+                maxLength={12}
                 className="code-input"
               />
             </div>
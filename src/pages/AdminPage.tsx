@@ -37,10 +37,8 @@ const AdminPage = () => {
     }
   };
 
-  const handleLogout = () => {
-    localStorage.removeItem('authToken');
-    localStorage.removeItem('userRole');
-    localStorage.removeItem('userName');
+  const handleLogout = async () => {
+    await api.logout();
     navigate('/');
   };
 
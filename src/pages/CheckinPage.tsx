@@ -0,0 +1,58 @@
+import { useState, useEffect, useRef } from 'react';
+import { useNavigate, useLocation } from 'react-router-dom';
+import * as api from '../utils/api';
+
+// CheckinPage is opened from the QR code shown by the teacher and submits the
+// signed check-in link for the logged in student.
+const CheckinPage = () => {
+  const [message, setMessage] = useState('');
+  const [error, setError] = useState('');
+  const submitted = useRef(false);
+  const navigate = useNavigate();
+  const location = useLocation();
+
+  useEffect(() => {
+    if (submitted.current) return;
+    submitted.current = true;
+
+    if (!localStorage.getItem('authToken')) {
+      sessionStorage.setItem('pendingCheckin', location.pathname + location.search);
+      navigate('/');
+      return;
+    }
+
+    const submit = async () => {
+      try {
+        const response = await api.scanAttendance(window.location.href);
+        setMessage(response.message || 'Посещение успешно отмечено!');
+      } catch (err: any) {
+        if (err.code === 'not_in_lesson_group') {
+          setError('Вы не состоите в группе, для которой проводится это занятие.');
+        } else if (err.code === 'too_many_attempts') {
+          setError('Слишком много неверных кодов. Попробуйте снова через несколько минут.');
+        } else if (err.code === 'invalid_checkin') {
+          setError('Ссылка недействительна или устарела. Отсканируйте QR-код ещё раз.');
+        } else {
+          setError(err.message || 'Ошибка при отметке посещения.');
+        }
+      }
+    };
+    submit();
+  }, [navigate, location]);
+
+  return (
+    <div className="container">
+      <main>
+        <h2>Отметка посещения</h2>
+        {!message && !error && <p>Отправка...</p>}
+        {message && <p className="message success">{message}</p>}
+        {error && <p className="error">{error}</p>}
+        <button onClick={() => navigate('/student')} className="btn-secondary">
+          К расписанию
+        </button>
+      </main>
+    </div>
+  );
+};
+
+export default CheckinPage;
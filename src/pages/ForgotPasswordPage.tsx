@@ -0,0 +1,45 @@
+import { useState } from 'react';
+import { Link } from 'react-router-dom';
+import * as api from '../utils/api';
+
+const ForgotPasswordPage = () => {
+  const [login, setLogin] = useState('');
+  const [message, setMessage] = useState('');
+  const [error, setError] = useState('');
+
+  const handleSubmit = async () => {
+    try {
+      setError('');
+      await api.forgotPassword(login);
+      setMessage('Если такой пользователь существует, на его email отправлена ссылка для сброса пароля.');
+    } catch (err: any) {
+      setError(err.message || 'Не удалось отправить запрос');
+    }
+  };
+
+  return (
+    <div className="login-page">
+      <div className="login-container">
+        <h2>Восстановление пароля</h2>
+        {error && <p className="error">{error}</p>}
+        {message ? (
+          <p className="message success">{message}</p>
+        ) : (
+          <>
+            <input
+              placeholder="Логин или email"
+              value={login}
+              onChange={e => setLogin(e.target.value)}
+            />
+            <button onClick={handleSubmit}>Отправить ссылку</button>
+          </>
+        )}
+        <p style={{ textAlign: 'center', marginTop: '1rem' }}>
+          <Link to="/">Вернуться ко входу</Link>
+        </p>
+      </div>
+    </div>
+  );
+};
+
+export default ForgotPasswordPage;
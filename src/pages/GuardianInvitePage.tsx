@@ -0,0 +1,95 @@
+import { useState } from 'react';
+import { Link, useLocation, useNavigate, useSearchParams } from 'react-router-dom';
+import * as api from '../utils/api';
+
+// GuardianInvitePage is opened from the invite mailed to a parent. New parents
+// create an account here; logged in users link the student to their account.
+const GuardianInvitePage = () => {
+  const [searchParams] = useSearchParams();
+  const [identifier, setIdentifier] = useState('');
+  const [name, setName] = useState('');
+  const [password, setPassword] = useState('');
+  const [message, setMessage] = useState('');
+  const [error, setError] = useState('');
+  const [accountExists, setAccountExists] = useState(false);
+  const navigate = useNavigate();
+  const location = useLocation();
+  const token = searchParams.get('token') || '';
+  const loggedIn = !!localStorage.getItem('authToken');
+
+  const handleError = (err: any) => {
+    if (err.code === 'invalid_token') {
+      setError('Приглашение недействительно или устарело. Попросите отправить новое.');
+    } else if (err.code === 'invite_email_mismatch') {
+      setError('Приглашение отправлено на другой email. Войдите в аккаунт с этим адресом.');
+    } else if (err.code === 'account_exists') {
+      setAccountExists(true);
+      setError('Аккаунт с этим email уже существует. Войдите в него, чтобы принять приглашение.');
+    } else {
+      setError(err.message || 'Не удалось принять приглашение');
+    }
+  };
+
+  const handleCreate = async () => {
+    try {
+      setError('');
+      await api.acceptGuardianInvite(token, identifier, name, password);
+      setMessage('Аккаунт создан. Теперь вы можете войти.');
+    } catch (err: any) {
+      handleError(err);
+    }
+  };
+
+  const handleLink = async () => {
+    try {
+      setError('');
+      await api.linkGuardianInvite(token);
+      setMessage('Студент привязан к вашему аккаунту.');
+    } catch (err: any) {
+      handleError(err);
+    }
+  };
+
+  const handleLogin = () => {
+    sessionStorage.setItem('pendingGuardianInvite', location.pathname + location.search);
+    navigate('/');
+  };
+
+  return (
+    <div className="login-page">
+      <div className="login-container">
+        <h2>Приглашение для родителя</h2>
+        {error && <p className="error">{error}</p>}
+        {message ? (
+          <>
+            <p className="message success">{message}</p>
+            <p style={{ textAlign: 'center', marginTop: '1rem' }}>
+              {loggedIn ? <Link to="/guardian">Перейти к посещаемости</Link> : <Link to="/">Перейти ко входу</Link>}
+            </p>
+          </>
+        ) : loggedIn ? (
+          <button onClick={handleLink}>Принять приглашение</button>
+        ) : accountExists ? (
+          <button onClick={handleLogin}>Войти</button>
+        ) : (
+          <>
+            <input placeholder="Логин" value={identifier} onChange={e => setIdentifier(e.target.value)} />
+            <input placeholder="ФИО" value={name} onChange={e => setName(e.target.value)} />
+            <input
+              placeholder="Пароль"
+              type="password"
+              value={password}
+              onChange={e => setPassword(e.target.value)}
+            />
+            <button onClick={handleCreate}>Создать аккаунт</button>
+            <p style={{ textAlign: 'center', marginTop: '1rem' }}>
+              Уже есть аккаунт? <a href="#" onClick={e => { e.preventDefault(); handleLogin(); }}>Войти</a>
+            </p>
+          </>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default GuardianInvitePage;
@@ -0,0 +1,111 @@
+import { useState, useEffect } from 'react';
+import { useNavigate } from 'react-router-dom';
+import * as api from '../utils/api';
+import { statusLabel } from '../utils/status';
+import type { AttendanceRecord, AttendanceStats, User } from '../types';
+
+// GuardianPage shows parents the attendance of their linked students.
+const GuardianPage = () => {
+  const [user, setUser] = useState<User | null>(null);
+  const [students, setStudents] = useState<User[]>([]);
+  const [selected, setSelected] = useState<User | null>(null);
+  const [stats, setStats] = useState<AttendanceStats | null>(null);
+  const [attendanceRecords, setAttendanceRecords] = useState<AttendanceRecord[]>([]);
+  const [error, setError] = useState('');
+  const navigate = useNavigate();
+
+  useEffect(() => {
+    const storedUser = localStorage.getItem('user');
+    if (storedUser) {
+      setUser(JSON.parse(storedUser));
+    } else {
+      navigate('/');
+    }
+
+    api.getGuardianStudents()
+      .then((data: User[]) => {
+        setStudents(data);
+        if (data.length > 0) setSelected(data[0]);
+      })
+      .catch(err => {
+        setError('Не удалось загрузить данные.');
+        console.error(err);
+      });
+  }, [navigate]);
+
+  useEffect(() => {
+    if (!selected) return;
+    Promise.all([api.getGuardianStudentStats(selected.id), api.getGuardianStudentAttendance(selected.id)])
+      .then(([statsData, attendanceData]) => {
+        setStats(statsData);
+        setAttendanceRecords(attendanceData);
+      })
+      .catch(err => {
+        setError('Не удалось загрузить посещаемость.');
+        console.error(err);
+      });
+  }, [selected]);
+
+  const handleLogout = async () => {
+    await api.logout();
+    navigate('/');
+  };
+
+  return (
+    <div className="container">
+      <header>
+        <h1>Портал родителя</h1>
+        <div className="user-info">
+          <span>Добро пожаловать, {user?.name || 'Родитель'}</span>
+          <button onClick={handleLogout} className="logout-button">Выйти</button>
+        </div>
+      </header>
+      <main>
+        {error && <p className="error">{error}</p>}
+        {students.length === 0 ? (
+          <p>К вашему аккаунту пока не привязан ни один студент</p>
+        ) : (
+          <>
+            {students.length > 1 && (
+              <select value={selected?.id} onChange={e => setSelected(students.find(s => s.id === Number(e.target.value)) || null)}>
+                {students.map(student => (
+                  <option key={student.id} value={student.id}>{student.name}</option>
+                ))}
+              </select>
+            )}
+            {selected && (
+              <h2>{selected.name}{selected.group ? `, ${selected.group.name}` : ''}</h2>
+            )}
+            {stats && (
+              <p>
+                Посещено {stats.attended} из {stats.sessions} занятий ({stats.rate}%), опозданий {stats.late},
+                пропущено {stats.missed}, по уважительной причине {stats.excused}
+              </p>
+            )}
+            <div className="attendance-history">
+              <h2>История посещений</h2>
+              {attendanceRecords.length === 0 ? (
+                <p>Отметок о посещении пока нет</p>
+              ) : (
+                <div className="history-list">
+                  {attendanceRecords.map(record => (
+                    <div key={record.id} className="history-item">
+                      <div className="history-info">
+                        <h4>{record.lesson.name}</h4>
+                        <p>{new Date(record.submitted_at).toLocaleDateString('ru-RU')}</p>
+                        <p>{new Date(record.submitted_at).toLocaleTimeString('ru-RU')}</p>
+                        <p className={`status status-${record.status}`}>{statusLabel(record)}</p>
+                      </div>
+                    </div>
+                  ))}
+                </div>
+              )}
+            </div>
+          </>
+        )}
+      </main>
+    </div>
+  );
+};
+
+export default GuardianPage;
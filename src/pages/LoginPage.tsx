@@ -8,26 +8,50 @@ const LoginPage = () => {
   const [identifier, setIdentifier] = useState('');
   const [password, setPassword] = useState('');
   const [error, setError] = useState('');
+  const [unverified, setUnverified] = useState(false);
   const navigate = useNavigate();
 
+  const handleResend = async () => {
+    try {
+      await api.resendVerification(identifier);
+      setUnverified(false);
+      setError('Письмо отправлено повторно. Проверьте почту.');
+    } catch (err: any) {
+      setError(err.message || 'Не удалось отправить письмо');
+    }
+  };
+
   const handleLogin = async () => {
     try {
       setError('');
+      setUnverified(false);
       const response = await api.login(identifier, password);
       if (response.token && response.user) {
         localStorage.setItem('authToken', response.token);
+        localStorage.setItem('refreshToken', response.refresh_token);
         localStorage.setItem('user', JSON.stringify(response.user));
 
         switch (response.user.role) {
-          case 'student':
-            navigate('/student');
+          case 'student': {
+            // Finish a QR check-in that was opened before logging in
+            const pendingCheckin = sessionStorage.getItem('pendingCheckin');
+            sessionStorage.removeItem('pendingCheckin');
+            navigate(pendingCheckin || '/student');
             break;
+          }
           case 'teacher':
             navigate('/teacher');
             break;
           case 'admin':
             navigate('/admin');
             break;
+          case 'guardian': {
+            // Finish accepting a guardian invite opened before logging in
+            const pendingInvite = sessionStorage.getItem('pendingGuardianInvite');
+            sessionStorage.removeItem('pendingGuardianInvite');
+            navigate(pendingInvite || '/guardian');
+            break;
+          }
           default:
             navigate('/');
         }
@@ -35,7 +59,18 @@ const LoginPage = () => {
         setError('Ошибка входа: неверный ответ от сервера.');
       }
     } catch (err: any) {
-      setError(err.message || 'Ошибка входа');
+      if (err.code === 'email_not_verified') {
+        setUnverified(true);
+        setError('Подтвердите email по ссылке из письма, отправленного при регистрации.');
+      } else if (err.code === 'account_pending') {
+        setError('Ваша регистрация ещё не одобрена администратором.');
+      } else if (err.code === 'account_rejected') {
+        setError('Ваша регистрация отклонена администратором.');
+      } else if (err.code === 'login_locked') {
+        setError('Слишком много неудачных попыток входа. Попробуйте позже.');
+      } else {
+        setError(err.message || 'Ошибка входа');
+      }
     }
   };
 
@@ -44,6 +79,11 @@ const LoginPage = () => {
       <div className="login-container">
         <h2>Вход в систему</h2>
         {error && <p className="error">{error}</p>}
+        {unverified && (
+          <button type="button" onClick={handleResend} className="btn-secondary">
+            Отправить письмо ещё раз
+          </button>
+        )}
         <input
           placeholder="Логин"
           value={identifier}
@@ -59,6 +99,9 @@ const LoginPage = () => {
         <p style={{ textAlign: 'center', marginTop: '1rem' }}>
           Нет аккаунта? <Link to="/register">Зарегистрироваться</Link>
         </p>
+        <p style={{ textAlign: 'center' }}>
+          <Link to="/forgot-password">Забыли пароль?</Link>
+        </p>
       </div>
     </div>
   );
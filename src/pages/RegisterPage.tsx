@@ -9,6 +9,8 @@ const RegisterPage = () => {
   const [name, setName] = useState('');
   const [email, setEmail] = useState('');
   const [groupId, setGroupId] = useState<string>('');
+  const [inviteCode, setInviteCode] = useState('');
+  const [policy, setPolicy] = useState('open');
   const [groups, setGroups] = useState<Group[]>([]);
   const [error, setError] = useState('');
   const [message, setMessage] = useState('');
@@ -25,10 +27,17 @@ const RegisterPage = () => {
       }
     };
     fetchGroups();
+    api.getRegistrationPolicy()
+      .then(data => setPolicy(data.policy))
+      .catch(err => console.error('Error fetching registration policy:', err));
   }, []);
 
   const handleRegister = async () => {
-    if (!groupId) {
+    if (policy === 'invite' && !inviteCode.trim()) {
+      setError('Пожалуйста, введите код приглашения.');
+      return;
+    }
+    if (policy !== 'invite' && !groupId) {
       setError('Пожалуйста, выберите группу.');
       return;
     }
@@ -40,12 +49,16 @@ const RegisterPage = () => {
         password,
         name,
         email,
-        group_id: Number(groupId),
+        ...(policy === 'invite' ? { invite_code: inviteCode.trim() } : { group_id: Number(groupId) }),
       });
       setMessage(response.message || 'Регистрация прошла успешно!');
-      setTimeout(() => navigate('/'), 2000);
+      setTimeout(() => navigate('/'), 5000);
     } catch (err: any) {
-      setError(err.message || 'Ошибка регистрации');
+      if (err.code === 'invalid_invite') {
+        setError('Код приглашения недействителен или срок его действия истек.');
+      } else {
+        setError(err.message || 'Ошибка регистрации');
+      }
     }
   };
 
@@ -77,14 +90,25 @@ const RegisterPage = () => {
           value={email}
           onChange={e => setEmail(e.target.value)}
         />
-        <select value={groupId} onChange={e => setGroupId(e.target.value)} required>
-          <option value="" disabled>-- Выберите группу --</option>
-          {groups.map(group => (
-            <option key={group.id} value={group.id}>
-              {group.name}
-            </option>
-          ))}
-        </select>
+        {policy === 'invite' ? (
+          <input
+            placeholder="Код приглашения"
+            value={inviteCode}
+            onChange={e => setInviteCode(e.target.value)}
+          />
+        ) : (
+          <select value={groupId} onChange={e => setGroupId(e.target.value)} required>
+            <option value="" disabled>-- Выберите группу --</option>
+            {groups.map(group => (
+              <option key={group.id} value={group.id}>
+                {group.name}
+              </option>
+            ))}
+          </select>
+        )}
+        {policy === 'approval' && (
+          <p>После регистрации аккаунт должен одобрить администратор.</p>
+        )}
         <button onClick={handleRegister}>Зарегистрироваться</button>
         <p style={{ textAlign: 'center', marginTop: '1rem' }}>
           Уже есть аккаунт? <Link to="/">Войти</Link>
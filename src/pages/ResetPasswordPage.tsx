@@ -0,0 +1,62 @@
+import { useState } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import * as api from '../utils/api';
+
+const ResetPasswordPage = () => {
+  const [searchParams] = useSearchParams();
+  const [password, setPassword] = useState('');
+  const [confirmation, setConfirmation] = useState('');
+  const [message, setMessage] = useState('');
+  const [error, setError] = useState('');
+
+  const handleSubmit = async () => {
+    if (password !== confirmation) {
+      setError('Пароли не совпадают');
+      return;
+    }
+    try {
+      setError('');
+      await api.resetPassword(searchParams.get('token') || '', password);
+      setMessage('Пароль изменен. Теперь вы можете войти с новым паролем.');
+    } catch (err: any) {
+      if (err.code === 'invalid_token') {
+        setError('Ссылка недействительна или устарела. Запросите сброс пароля ещё раз.');
+      } else {
+        setError(err.message || 'Не удалось изменить пароль');
+      }
+    }
+  };
+
+  return (
+    <div className="login-page">
+      <div className="login-container">
+        <h2>Новый пароль</h2>
+        {error && <p className="error">{error}</p>}
+        {message ? (
+          <p className="message success">{message}</p>
+        ) : (
+          <>
+            <input
+              placeholder="Новый пароль"
+              type="password"
+              value={password}
+              onChange={e => setPassword(e.target.value)}
+            />
+            <input
+              placeholder="Повторите пароль"
+              type="password"
+              value={confirmation}
+              onChange={e => setConfirmation(e.target.value)}
+            />
+            <button onClick={handleSubmit}>Сохранить</button>
+          </>
+        )}
+        <p style={{ textAlign: 'center', marginTop: '1rem' }}>
+          <Link to="/">Вернуться ко входу</Link>
+        </p>
+      </div>
+    </div>
+  );
+};
+
+export default ResetPasswordPage;
@@ -3,7 +3,14 @@ import { useNavigate } from 'react-router-dom';
 import Schedule from '../components/Schedule';
 import CodeEntryModal from '../components/CodeEntryModal';
 import * as api from '../utils/api';
-import type { Lesson, AttendanceRecord, User } from '../types';
+import { statusLabel } from '../utils/status';
+import type { Lesson, AttendanceRecord, ExcuseRequest, GuardianLink, User } from '../types';
+
+const excuseStatusLabels: Record<ExcuseRequest['status'], string> = {
+  pending: 'на рассмотрении',
+  approved: 'принята',
+  rejected: 'отклонена',
+};
 
 const StudentPage = () => {
   const [user, setUser] = useState<User | null>(null);
@@ -11,6 +18,13 @@ const StudentPage = () => {
   const [selectedLesson, setSelectedLesson] = useState<Lesson | null>(null);
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [attendanceRecords, setAttendanceRecords] = useState<AttendanceRecord[]>([]);
+  const [guardians, setGuardians] = useState<GuardianLink[]>([]);
+  const [guardianEmail, setGuardianEmail] = useState('');
+  const [excuses, setExcuses] = useState<ExcuseRequest[]>([]);
+  const [excuseStart, setExcuseStart] = useState('');
+  const [excuseEnd, setExcuseEnd] = useState('');
+  const [excuseReason, setExcuseReason] = useState('');
+  const [excuseFile, setExcuseFile] = useState<File | null>(null);
   const [error, setError] = useState('');
   const [message, setMessage] = useState('');
   const navigate = useNavigate();
@@ -32,6 +46,9 @@ const StudentPage = () => {
         
         const attendanceData = await api.getStudentAttendance();
         setAttendanceRecords(attendanceData);
+
+        setGuardians(await api.getMyGuardians());
+        setExcuses(await api.getMyExcuses());
       } catch (err) {
         setError('Не удалось загрузить данные.');
         console.error(err);
@@ -59,7 +76,13 @@ const StudentPage = () => {
 
       setIsModalOpen(false);
     } catch (err: any) {
-      setError(err.message || 'Ошибка при отправке кода.');
+      if (err.code === 'not_in_lesson_group') {
+        setError('Вы не состоите в группе, для которой проводится это занятие.');
+      } else if (err.code === 'too_many_attempts') {
+        setError('Слишком много неверных кодов. Попробуйте снова через несколько минут.');
+      } else {
+        setError(err.message || 'Ошибка при отправке кода.');
+      }
     } finally {
       setTimeout(() => {
         setMessage('');
@@ -68,9 +91,65 @@ const StudentPage = () => {
     }
   };
 
-  const handleLogout = () => {
-    localStorage.removeItem('authToken');
-    localStorage.removeItem('user');
+  const handleInviteGuardian = async () => {
+    try {
+      setError('');
+      await api.inviteGuardian(guardianEmail);
+      setMessage(`Приглашение отправлено на ${guardianEmail}`);
+      setGuardianEmail('');
+    } catch (err: any) {
+      setError(err.message || 'Не удалось отправить приглашение.');
+    }
+  };
+
+  const handleRemoveGuardian = async (link: GuardianLink) => {
+    if (!window.confirm(`Закрыть доступ для ${link.guardian.name}?`)) return;
+    try {
+      await api.removeGuardian(link.id);
+      setGuardians(guardians.filter(g => g.id !== link.id));
+    } catch (err: any) {
+      setError(err.message || 'Не удалось закрыть доступ.');
+    }
+  };
+
+  const handleSubmitExcuse = async () => {
+    if (!excuseFile) return;
+    try {
+      setError('');
+      const excuse = await api.submitExcuse(excuseStart, excuseEnd, excuseReason, excuseFile);
+      setExcuses([excuse, ...excuses]);
+      setExcuseStart('');
+      setExcuseEnd('');
+      setExcuseReason('');
+      setExcuseFile(null);
+      setMessage('Справка отправлена на рассмотрение.');
+    } catch (err: any) {
+      setError(err.message || 'Не удалось отправить справку.');
+    }
+  };
+
+  const handleOpenExcuse = async (excuse: ExcuseRequest) => {
+    try {
+      const url = await api.getExcuseFile(excuse.id);
+      window.open(url, '_blank');
+      setTimeout(() => URL.revokeObjectURL(url), 60000);
+    } catch (err: any) {
+      setError(err.message || 'Не удалось открыть файл.');
+    }
+  };
+
+  const handleWithdrawExcuse = async (excuse: ExcuseRequest) => {
+    if (!window.confirm('Отозвать справку?')) return;
+    try {
+      await api.withdrawExcuse(excuse.id);
+      setExcuses(excuses.filter(e => e.id !== excuse.id));
+    } catch (err: any) {
+      setError(err.message || 'Не удалось отозвать справку.');
+    }
+  };
+
+  const handleLogout = async () => {
+    await api.logout();
     navigate('/');
   };
 
@@ -107,12 +186,74 @@ const StudentPage = () => {
                     <h4>{record.lesson.name}</h4>
                     <p>{new Date(record.submitted_at).toLocaleDateString('ru-RU')}</p>
                     <p>{new Date(record.submitted_at).toLocaleTimeString('ru-RU')}</p>
+                    <p className={`status status-${record.status}`}>{statusLabel(record)}</p>
                   </div>
                 </div>
               ))}
             </div>
           )}
         </div>
+
+        <div className="guardians">
+          <h2>Родители</h2>
+          <p>Родители видят вашу посещаемость, но не могут ничего изменить.</p>
+          {guardians.map(link => (
+            <div key={link.id} className="history-item">
+              <span>{link.guardian.name} ({link.guardian.email})</span>
+              <button onClick={() => handleRemoveGuardian(link)} className="btn-secondary">Закрыть доступ</button>
+            </div>
+          ))}
+          <input
+            type="email"
+            placeholder="Email родителя"
+            value={guardianEmail}
+            onChange={e => setGuardianEmail(e.target.value)}
+          />
+          <button onClick={handleInviteGuardian} className="btn-primary" disabled={!guardianEmail}>
+            Пригласить
+          </button>
+        </div>
+
+        <div className="excuses">
+          <h2>Справки</h2>
+          <p>После одобрения куратором пропуски за указанные дни считаются пропусками по уважительной причине.</p>
+          {excuses.map(excuse => (
+            <div key={excuse.id} className="history-item">
+              <span>
+                {new Date(excuse.start_date).toLocaleDateString('ru-RU')} – {new Date(excuse.end_date).toLocaleDateString('ru-RU')}
+                {excuse.reason && `: ${excuse.reason}`}
+              </span>
+              <span className={`excuse-status excuse-${excuse.status}`}>
+                {excuseStatusLabels[excuse.status]}
+                {excuse.review_comment && ` (${excuse.review_comment})`}
+              </span>
+              <button onClick={() => handleOpenExcuse(excuse)} className="btn-secondary">{excuse.file_name}</button>
+              {excuse.status === 'pending' && (
+                <button onClick={() => handleWithdrawExcuse(excuse)} className="btn-secondary">Отозвать</button>
+              )}
+            </div>
+          ))}
+          <input type="date" value={excuseStart} onChange={e => setExcuseStart(e.target.value)} />
+          <input type="date" value={excuseEnd} onChange={e => setExcuseEnd(e.target.value)} />
+          <input
+            type="text"
+            placeholder="Причина"
+            value={excuseReason}
+            onChange={e => setExcuseReason(e.target.value)}
+          />
+          <input
+            type="file"
+            accept="application/pdf,image/jpeg"
+            onChange={e => setExcuseFile(e.target.files?.[0] || null)}
+          />
+          <button
+            onClick={handleSubmitExcuse}
+            className="btn-primary"
+            disabled={!excuseStart || !excuseEnd || !excuseFile}
+          >
+            Отправить справку
+          </button>
+        </div>
       </main>
 
       {selectedLesson && (
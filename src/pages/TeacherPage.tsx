@@ -4,7 +4,7 @@ import Schedule from '../components/Schedule';
 import CodeDisplayModal from '../components/CodeDisplayModal';
 import AttendanceList from '../components/AttendanceList';
 import * as api from '../utils/api';
-import type { Lesson, AttendanceRecord, GeneratedCode, User } from '../types';
+import type { Lesson, CodeAttempt, GeneratedCode, Roster, RosterEntry, User } from '../types';
 
 const TeacherPage = () => {
   const [user, setUser] = useState<User | null>(null);
@@ -12,9 +12,12 @@ const TeacherPage = () => {
   const [selectedLesson, setSelectedLesson] = useState<Lesson | null>(null);
   const [isCodeModalOpen, setIsCodeModalOpen] = useState(false);
   const [currentCode, setCurrentCode] = useState<GeneratedCode | null>(null);
-  const [attendanceRecords, setAttendanceRecords] = useState<any[]>([]);
+  const [roster, setRoster] = useState<Roster | null>(null);
+  const [codeAttempts, setCodeAttempts] = useState<CodeAttempt[]>([]);
   const [message, setMessage] = useState('');
   const [generatedCode, setGeneratedCode] = useState<string | null>(null);
+  const [rotatingCode, setRotatingCode] = useState(false);
+  const [qrSrc, setQrSrc] = useState<string | null>(null);
   const [isModalOpen, setIsModalOpen] = useState(false);
   const [error, setError] = useState('');
   const navigate = useNavigate();
@@ -44,8 +47,8 @@ const TeacherPage = () => {
     if (!selectedLesson) return;
     const intervalId = setInterval(async () => {
       try {
-        const data = await api.getLessonAttendance(selectedLesson.id);
-        setAttendanceRecords(data);
+        setRoster(await api.getLessonRoster(selectedLesson.id));
+        setCodeAttempts(await api.getCodeAttempts(selectedLesson.id));
       } catch (error) {
         console.error('Failed to fetch attendance:', error);
       }
@@ -54,14 +57,43 @@ const TeacherPage = () => {
     return () => clearInterval(intervalId);
   }, [selectedLesson]);
 
+  useEffect(() => {
+    if (!isModalOpen || !selectedLesson || !rotatingCode) return;
+    const intervalId = setInterval(async () => {
+      try {
+        const data = await api.getCurrentCode(selectedLesson.id);
+        setGeneratedCode(data.code);
+      } catch (error) {
+        console.error('Failed to fetch current code:', error);
+      }
+    }, 2000); // Rotating codes change every 10-30 seconds
+
+    return () => clearInterval(intervalId);
+  }, [isModalOpen, selectedLesson, rotatingCode]);
+
+  // Re-render the QR whenever the shown code changes, rotating codes included
+  useEffect(() => {
+    if (!isModalOpen || !selectedLesson || !generatedCode) return;
+    let objectUrl: string | null = null;
+    api.getCodeQR(selectedLesson.id)
+      .then(url => {
+        objectUrl = url;
+        setQrSrc(url);
+      })
+      .catch(error => console.error('Failed to fetch QR code:', error));
+
+    return () => {
+      if (objectUrl) URL.revokeObjectURL(objectUrl);
+    };
+  }, [isModalOpen, selectedLesson, generatedCode]);
+
   const handleLessonClick = async (lesson: Lesson) => {
     setSelectedLesson(lesson);
     try {
       const code = await api.generateCode(lesson.id);
       setCurrentCode(code);
       setIsCodeModalOpen(true);
-      const records = await api.getLessonAttendance(lesson.id);
-      setAttendanceRecords(records);
+      setRoster(await api.getLessonRoster(lesson.id));
     } catch (error: any) {
       console.error('Failed to generate code:', error);
       setMessage(error.message || 'Failed to start lesson.');
@@ -70,16 +102,17 @@ const TeacherPage = () => {
 
   const handleGenerateCode = async (lesson: Lesson) => {
     setSelectedLesson(lesson);
-    setAttendanceRecords([]); // Clear old records
+    setRoster(null); // Clear old records
+    setCodeAttempts([]);
     try {
       setError('');
       setMessage('');
       const response = await api.generateCode(lesson.id);
       setGeneratedCode(response.code);
+      setRotatingCode(response.mode === 'rotating');
       setMessage(`Код для занятия '${lesson.name}' сгенерирован.`);
       setIsModalOpen(true);
-      const records = await api.getLessonAttendance(lesson.id);
-      setAttendanceRecords(records);
+      setRoster(await api.getLessonRoster(lesson.id));
     } catch (error: any) {
       setError(error.message || 'Не удалось сгенерировать код.');
     }
@@ -92,6 +125,7 @@ const TeacherPage = () => {
       setMessage(`Код для занятия '${selectedLesson.name}' остановлен.`);
       setIsModalOpen(false);
       setGeneratedCode(null);
+      setQrSrc(null);
     } catch (err: any) {
       setError(err.message || 'Не удалось остановить код.');
     }
@@ -101,8 +135,7 @@ const TeacherPage = () => {
     if (!selectedLesson) return;
     try {
       setMessage('Обновление списка...');
-      const records = await api.getLessonAttendance(selectedLesson.id);
-      setAttendanceRecords(records);
+      setRoster(await api.getLessonRoster(selectedLesson.id));
       setMessage('Список посещаемости обновлен.');
     } catch (err) {
       setError('Не удалось обновить список.');
@@ -111,9 +144,47 @@ const TeacherPage = () => {
     }
   };
 
-  const handleLogout = () => {
-    localStorage.removeItem('authToken');
-    localStorage.removeItem('user');
+  // Manual marks need a reason, which ends up in the lesson's change log
+  const handleStatusChange = async (entry: RosterEntry, status: RosterEntry['status']) => {
+    if (!selectedLesson || !roster) return;
+    const reason = window.prompt(`Причина изменения отметки (${entry.student.name}):`)?.trim();
+    if (!reason) return;
+    try {
+      setError('');
+      if (status === 'absent') {
+        await api.removeStudentAttendance(selectedLesson.id, entry.student.id, roster.session.id, reason);
+      } else {
+        let minutesLate = 0;
+        if (status === 'late') {
+          minutesLate = Number(window.prompt('Опоздание, минут:', String(entry.minutes_late || 0)));
+          if (!Number.isInteger(minutesLate) || minutesLate < 0) {
+            setError('Укажите опоздание целым числом минут.');
+            return;
+          }
+        }
+        await api.setStudentAttendance(selectedLesson.id, entry.student.id, roster.session.id, {
+          status,
+          minutes_late: minutesLate,
+          reason,
+        });
+      }
+      setRoster(await api.getLessonRoster(selectedLesson.id));
+    } catch (err: any) {
+      setError(err.message || 'Не удалось изменить отметку.');
+    }
+  };
+
+  const handleDownloadRoster = async () => {
+    if (!selectedLesson) return;
+    try {
+      await api.downloadLessonRoster(selectedLesson.id);
+    } catch (err: any) {
+      setError(err.message || 'Не удалось скачать список.');
+    }
+  };
+
+  const handleLogout = async () => {
+    await api.logout();
     navigate('/');
   };
 
@@ -135,8 +206,9 @@ const TeacherPage = () => {
             <div className="attendance-header">
               <h3>Посещаемость: {selectedLesson.name}</h3>
               <button onClick={handleRefreshAttendance} className="btn-secondary">Обновить список</button>
+              <button onClick={handleDownloadRoster} className="btn-secondary">Скачать CSV</button>
             </div>
-            <AttendanceList attendanceRecords={attendanceRecords} />
+            <AttendanceList roster={roster} codeAttempts={codeAttempts} onStatusChange={handleStatusChange} />
           </div>
         )}
       </main>
@@ -155,6 +227,7 @@ const TeacherPage = () => {
         <CodeDisplayModal
           lesson={selectedLesson}
           code={generatedCode}
+          qrSrc={qrSrc}
           isOpen={isModalOpen}
           onClose={() => setIsModalOpen(false)}
           onStop={handleStopCode}
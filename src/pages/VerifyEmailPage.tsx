@@ -0,0 +1,42 @@
+import { useState, useEffect, useRef } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import * as api from '../utils/api';
+
+// VerifyEmailPage is opened from the link mailed on registration.
+const VerifyEmailPage = () => {
+  const [searchParams] = useSearchParams();
+  const [message, setMessage] = useState('');
+  const [error, setError] = useState('');
+  const submitted = useRef(false);
+
+  useEffect(() => {
+    if (submitted.current) return;
+    submitted.current = true;
+
+    api.verifyEmail(searchParams.get('token') || '')
+      .then(() => setMessage('Email подтвержден. Теперь вы можете войти.'))
+      .catch((err: any) => {
+        if (err.code === 'invalid_token') {
+          setError('Ссылка недействительна или устарела. Запросите новое письмо на странице входа.');
+        } else {
+          setError(err.message || 'Не удалось подтвердить email');
+        }
+      });
+  }, [searchParams]);
+
+  return (
+    <div className="login-page">
+      <div className="login-container">
+        <h2>Подтверждение email</h2>
+        {!message && !error && <p>Проверка ссылки...</p>}
+        {message && <p className="message success">{message}</p>}
+        {error && <p className="error">{error}</p>}
+        <p style={{ textAlign: 'center', marginTop: '1rem' }}>
+          <Link to="/">Перейти ко входу</Link>
+        </p>
+      </div>
+    </div>
+  );
+};
+
+export default VerifyEmailPage;
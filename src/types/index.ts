@@ -10,7 +10,7 @@ export interface User {
   identifier: string;
   name: string;
   email: string;
-  role: 'student' | 'teacher' | 'admin';
+  role: 'student' | 'teacher' | 'admin' | 'guardian';
   group_id?: number;
   group?: Group;
 }
@@ -31,14 +31,104 @@ export interface AttendanceRecord {
   lesson_id: number;
   student_id: number;
   submitted_at: string;
+  status: 'present' | 'late' | 'absent' | 'excused';
+  minutes_late: number;
+  source: 'code' | 'manual' | 'excuse';
+  marked_by?: User;
   lesson: Lesson;
   student: User;
 }
 
+export interface AttendanceChange {
+  id: number;
+  session_id: number;
+  student_id: number;
+  old_status: string;
+  new_status: string;
+  reason: string;
+  changed_at: string;
+  student: User;
+  changed_by?: User;
+}
+
+export interface RosterEntry {
+  student: User;
+  status: AttendanceRecord['status'];
+  minutes_late: number;
+  submitted_at: string | null;
+  source: '' | 'code' | 'manual' | 'excuse';
+  marked_by?: User;
+}
+
+export interface RosterCount {
+  count: number;
+  percent: number;
+}
+
+export interface Roster {
+  lesson: Lesson;
+  session: { id: number; date: string };
+  students: RosterEntry[];
+  totals: {
+    expected: number;
+    present: RosterCount;
+    late: RosterCount;
+    absent: RosterCount;
+    excused: RosterCount;
+  };
+}
+
+export interface ExcuseRequest {
+  id: number;
+  student_id: number;
+  start_date: string;
+  end_date: string;
+  reason: string;
+  file_name: string;
+  content_type: string;
+  file_size: number;
+  status: 'pending' | 'approved' | 'rejected';
+  reviewed_at: string | null;
+  review_comment: string;
+  created_at: string;
+  reviewed_by?: User;
+}
+
+export interface GuardianLink {
+  id: number;
+  guardian_id: number;
+  student_id: number;
+  created_at: string;
+  guardian: User;
+}
+
+export interface AttendanceStats {
+  student: User;
+  sessions: number;
+  attended: number;
+  late: number;
+  excused: number;
+  missed: number;
+  rate: number;
+}
+
+export interface CodeAttempt {
+  id: number;
+  session_id: number;
+  student_id: number;
+  failures: number;
+  last_failed_at: string;
+  locked_until: string | null;
+  student: User;
+}
+
 export interface GeneratedCode {
   id: number;
   lesson_id: number;
+  mode: 'static' | 'rotating';
   code: string;
+  step_seconds?: number;
+  rotates_at?: string;
   expires_at: string;
   is_active: boolean;
 }
@@ -2,6 +2,75 @@ const API_BASE_URL = 'http://localhost:8080';
 
 const getAuthToken = () => localStorage.getItem('authToken');
 
+// ApiError carries the machine-readable `code` the backend adds to some errors.
+export class ApiError extends Error {
+  code?: string;
+
+  constructor(message: string, code?: string) {
+    super(message);
+    this.code = code;
+  }
+}
+
+// clearSession forgets the tokens and user of the current login.
+export const clearSession = () => {
+  localStorage.removeItem('authToken');
+  localStorage.removeItem('refreshToken');
+  localStorage.removeItem('user');
+};
+
+// Concurrent requests that hit an expired access token share one refresh, as
+// every refresh token can only be used once.
+let refreshing: Promise<boolean> | null = null;
+
+const refreshSession = () => {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = localStorage.getItem('refreshToken');
+      if (!refreshToken) return false;
+      const response = await fetch(`${API_BASE_URL}/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      });
+      if (!response.ok) return false;
+      const data = await response.json();
+      localStorage.setItem('authToken', data.token);
+      localStorage.setItem('refreshToken', data.refresh_token);
+      return true;
+    })()
+      .catch(() => false)
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+};
+
+// authorizedFetch sends the access token and, if it has expired, refreshes
+// the session once and retries. A session that cannot be refreshed ends in a
+// redirect to the login page.
+const authorizedFetch = async (url: string, options: RequestInit = {}, headers: Record<string, string> = {}) => {
+  const send = () => {
+    const token = getAuthToken();
+    return fetch(`${API_BASE_URL}${url}`, {
+      ...options,
+      headers: token ? { ...headers, Authorization: `Bearer ${token}` } : headers,
+    });
+  };
+
+  let response = await send();
+  if (response.status === 401 && url.startsWith('/api/')) {
+    if (await refreshSession()) {
+      response = await send();
+    } else {
+      clearSession();
+      window.location.assign('/');
+    }
+  }
+  return response;
+};
+
 const apiFetch = async (url: string, options: RequestInit = {}) => {
   const headers: Record<string, string> = {
     'Content-Type': 'application/json',
@@ -11,19 +80,11 @@ const apiFetch = async (url: string, options: RequestInit = {}) => {
     Object.assign(headers, options.headers);
   }
 
-  const token = getAuthToken();
-  if (token) {
-    headers['Authorization'] = `Bearer ${token}`;
-  }
-
-  const response = await fetch(`${API_BASE_URL}${url}`, {
-    ...options,
-    headers,
-  });
+  const response = await authorizedFetch(url, options, headers);
 
   if (!response.ok) {
     const errorData = await response.json().catch(() => ({ error: 'An unknown error occurred' }));
-    throw new Error(errorData.error || 'Request failed');
+    throw new ApiError(errorData.error || 'Request failed', errorData.code);
   }
 
   return response.json();
@@ -36,6 +97,75 @@ export const login = (identifier: string, password: string) => {
   });
 };
 
+export const logout = async () => {
+  const refreshToken = localStorage.getItem('refreshToken');
+  clearSession();
+  if (refreshToken) {
+    await apiFetch('/auth/logout', {
+      method: 'POST',
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    }).catch(() => undefined);
+  }
+};
+
+export const forgotPassword = (login: string) => {
+  return apiFetch('/auth/forgot-password', {
+    method: 'POST',
+    body: JSON.stringify({ login }),
+  });
+};
+
+export const resetPassword = (token: string, password: string) => {
+  return apiFetch('/auth/reset-password', {
+    method: 'POST',
+    body: JSON.stringify({ token, password }),
+  });
+};
+
+export const verifyEmail = (token: string) => {
+  return apiFetch('/auth/verify-email', {
+    method: 'POST',
+    body: JSON.stringify({ token }),
+  });
+};
+
+export const resendVerification = (login: string) => {
+  return apiFetch('/auth/resend-verification', {
+    method: 'POST',
+    body: JSON.stringify({ login }),
+  });
+};
+
+export const acceptGuardianInvite = (token: string, identifier: string, name: string, password: string) => {
+  return apiFetch('/auth/guardian-invite/accept', {
+    method: 'POST',
+    body: JSON.stringify({ token, identifier, name, password }),
+  });
+};
+
+export const linkGuardianInvite = (token: string) => {
+  return apiFetch('/api/guardian/invites/accept', {
+    method: 'POST',
+    body: JSON.stringify({ token }),
+  });
+};
+
+export const getGuardianStudents = () => {
+  return apiFetch('/api/guardian/students');
+};
+
+export const getGuardianStudentAttendance = (studentId: number) => {
+  return apiFetch(`/api/guardian/students/${studentId}/attendance`);
+};
+
+export const getGuardianStudentStats = (studentId: number) => {
+  return apiFetch(`/api/guardian/students/${studentId}/stats`);
+};
+
+export const getRegistrationPolicy = () => {
+  return apiFetch('/auth/registration-policy');
+};
+
 export const register = (userData: any) => {
   return apiFetch('/auth/register', {
     method: 'POST',
@@ -58,6 +188,21 @@ export const generateCode = (lesson_id: number) => {
   });
 };
 
+export const getCurrentCode = (lesson_id: number) => {
+  return apiFetch(`/api/teacher/lessons/${lesson_id}/code`);
+};
+
+// getCodeQR returns the QR image of the active code as an object URL; the
+// caller revokes it with URL.revokeObjectURL when it is no longer shown.
+export const getCodeQR = async (lesson_id: number, format: 'png' | 'svg' = 'svg') => {
+  const response = await authorizedFetch(`/api/teacher/lessons/${lesson_id}/code/qr?format=${format}`);
+  if (!response.ok) {
+    const errorData = await response.json().catch(() => ({ error: 'An unknown error occurred' }));
+    throw new ApiError(errorData.error || 'Request failed', errorData.code);
+  }
+  return URL.createObjectURL(await response.blob());
+};
+
 export const deactivateCode = (lesson_id: number) => {
   return apiFetch(`/api/teacher/lessons/${lesson_id}/code`, {
     method: 'DELETE',
@@ -69,6 +214,60 @@ export const getLessonAttendance = (lessonId: number) => {
   return apiFetch(`/api/teacher/attendance/${lessonId}`);
 };
 
+export const getLessonRoster = (lessonId: number) => {
+  return apiFetch(`/api/teacher/lessons/${lessonId}/roster`);
+};
+
+export const setStudentAttendance = (
+  lessonId: number,
+  studentId: number,
+  sessionId: number,
+  mark: { status: 'present' | 'late' | 'excused'; minutes_late?: number; reason: string },
+) => {
+  return apiFetch(`/api/teacher/lessons/${lessonId}/attendance/${studentId}?session_id=${sessionId}`, {
+    method: 'PUT',
+    body: JSON.stringify(mark),
+  });
+};
+
+export const removeStudentAttendance = (lessonId: number, studentId: number, sessionId: number, reason: string) => {
+  return apiFetch(`/api/teacher/lessons/${lessonId}/attendance/${studentId}?session_id=${sessionId}`, {
+    method: 'DELETE',
+    body: JSON.stringify({ reason }),
+  });
+};
+
+export const getAttendanceChanges = (lessonId: number, sessionId: number) => {
+  return apiFetch(`/api/teacher/lessons/${lessonId}/attendance/changes?session_id=${sessionId}`);
+};
+
+// downloadLessonRoster saves the roster of the latest session as a CSV file.
+export const downloadLessonRoster = async (lessonId: number) => {
+  const response = await authorizedFetch(`/api/teacher/lessons/${lessonId}/roster?format=csv`);
+  if (!response.ok) {
+    const errorData = await response.json().catch(() => ({ error: 'An unknown error occurred' }));
+    throw new ApiError(errorData.error || 'Request failed', errorData.code);
+  }
+  const filename = response.headers.get('Content-Disposition')?.match(/filename="(.+)"/)?.[1] || 'roster.csv';
+  const url = URL.createObjectURL(await response.blob());
+  const link = document.createElement('a');
+  link.href = url;
+  link.download = filename;
+  link.click();
+  URL.revokeObjectURL(url);
+};
+
+export const scanAttendance = (payload: string) => {
+  return apiFetch('/api/student/attendance/scan', {
+    method: 'POST',
+    body: JSON.stringify({ payload }),
+  });
+};
+
+export const getCodeAttempts = (lessonId: number) => {
+  return apiFetch(`/api/teacher/lessons/${lessonId}/attempts`);
+};
+
 export const getStudentAttendance = () => {
   return apiFetch('/api/student/attendance');
 };
@@ -80,6 +279,56 @@ export const submitAttendance = (lesson_id: number, code: string) => {
   });
 };
 
+export const getMyGuardians = () => {
+  return apiFetch('/api/student/guardians');
+};
+
+export const inviteGuardian = (email: string) => {
+  return apiFetch('/api/student/guardians/invites', {
+    method: 'POST',
+    body: JSON.stringify({ email }),
+  });
+};
+
+export const removeGuardian = (linkId: number) => {
+  return apiFetch(`/api/student/guardians/${linkId}`, { method: 'DELETE' });
+};
+
+export const getMyExcuses = () => {
+  return apiFetch('/api/student/excuses');
+};
+
+// submitExcuse uploads the document as multipart form data, so the browser
+// has to set the Content-Type with its boundary.
+export const submitExcuse = async (startDate: string, endDate: string, reason: string, file: File) => {
+  const form = new FormData();
+  form.append('start_date', startDate);
+  form.append('end_date', endDate);
+  form.append('reason', reason);
+  form.append('file', file);
+  const response = await authorizedFetch('/api/student/excuses', { method: 'POST', body: form });
+  const data = await response.json().catch(() => ({ error: 'An unknown error occurred' }));
+  if (!response.ok) {
+    throw new ApiError(data.error || 'Request failed', data.code);
+  }
+  return data;
+};
+
+// getExcuseFile returns the attached document as an object URL; the caller
+// revokes it with URL.revokeObjectURL when it is no longer shown.
+export const getExcuseFile = async (excuseId: number) => {
+  const response = await authorizedFetch(`/api/student/excuses/${excuseId}/file`);
+  if (!response.ok) {
+    const errorData = await response.json().catch(() => ({ error: 'An unknown error occurred' }));
+    throw new ApiError(errorData.error || 'Request failed', errorData.code);
+  }
+  return URL.createObjectURL(await response.blob());
+};
+
+export const withdrawExcuse = (excuseId: number) => {
+  return apiFetch(`/api/student/excuses/${excuseId}`, { method: 'DELETE' });
+};
+
 // Admin API
 export const adminGetUsers = () => apiFetch('/api/admin/users');
 export const adminCreateUser = (user: any) => apiFetch('/api/admin/users', { method: 'POST', body: JSON.stringify(user) });
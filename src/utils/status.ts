@@ -0,0 +1,28 @@
+import type { AttendanceRecord } from '../types';
+
+const statusText = (record: Pick<AttendanceRecord, 'status' | 'minutes_late'>) => {
+  switch (record.status) {
+    case 'late':
+      return `опоздание ${record.minutes_late} мин`;
+    case 'absent':
+      return 'отсутствовал';
+    case 'excused':
+      return 'уважительная причина';
+    default:
+      return 'присутствовал';
+  }
+};
+
+// statusLabel describes an attendance mark's status for display, noting marks
+// a teacher set by hand and absences excused by a certificate.
+export const statusLabel = (record: Pick<AttendanceRecord, 'status' | 'minutes_late'> & { source?: string }) => {
+  const text = statusText(record);
+  switch (record.source) {
+    case 'manual':
+      return `${text} (вручную)`;
+    case 'excuse':
+      return `${text} (справка)`;
+    default:
+      return text;
+  }
+};